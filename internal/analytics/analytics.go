@@ -180,12 +180,14 @@ func constructQueryParameters(cmd *cobra.Command, cmdErr error, analyticsSiteId
 	endTime := time.Now()
 	processingTime := endTime.Sub(startTime).Seconds()
 	params.Set("dimension19", fmt.Sprintf("%.2f", processingTime))
-	// 20 - Basic or OAuth
+	// 20 - Basic, OAuth or client certificate
 	oauthHost := config.GetString(cmd, "oauth-host")
-	if oauthHost == "" {
-		params.Set("dimension20", "BASIC")
-	} else {
+	if oauthHost != "" {
 		params.Set("dimension20", "OAUTH")
+	} else if config.GetString(cmd, "tmn-userid") == "" && config.GetString(cmd, "client-cert") != "" {
+		params.Set("dimension20", "CERTIFICATE")
+	} else {
+		params.Set("dimension20", "BASIC")
 	}
 	return params
 }
@@ -273,7 +273,7 @@ func (a *APIProduct) Exists(id string) (bool, error) {
 	callType := "Get APIProduct"
 	_, err := readOnlyCall(urlPath, callType, a.exe)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("%v call failed with response code = 404", callType) {
+		if errors.Is(err, httpclnt.ErrNotFound) {
 			return false, nil
 		} else {
 			return false, err
@@ -326,7 +326,7 @@ func (a *APIResource) Exists(id string) (bool, error) {
 	callType := "Get APIResource"
 	_, err := readOnlyCall(urlPath, callType, a.exe)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("%v call failed with response code = 404", callType) {
+		if errors.Is(err, httpclnt.ErrNotFound) {
 			return false, nil
 		} else {
 			return false, err
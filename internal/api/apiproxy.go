@@ -131,7 +131,7 @@ func (a *APIProxy) Exists(id string) (bool, error) {
 	callType := "Get APIProxy"
 	_, err := readOnlyCall(urlPath, callType, a.exe)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("%v call failed with response code = 404", callType) {
+		if errors.Is(err, httpclnt.ErrNotFound) {
 			return false, nil
 		} else {
 			return false, err
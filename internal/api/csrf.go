@@ -1,9 +1,13 @@
 package api
 
 import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
 	"github.com/engswee/flashpipe/internal/httpclnt"
 	"github.com/rs/zerolog/log"
-	"net/http"
 )
 
 type Csrf struct {
@@ -12,10 +16,11 @@ type Csrf struct {
 	csrfCookies []*http.Cookie
 }
 
-// NewCsrf returns an initialised Csrf instance.
+// NewCsrf returns an initialised Csrf instance, reusing the CSRF token cached in the HTTP executer if available.
 func NewCsrf(exe *httpclnt.HTTPExecuter) *Csrf {
 	c := new(Csrf)
 	c.exe = exe
+	c.token, c.csrfCookies = exe.CsrfToken()
 	return c
 }
 
@@ -34,6 +39,8 @@ func (c *Csrf) GetToken() (string, []*http.Cookie, error) {
 			c.token = resp.Header.Get("x-csrf-token")
 			c.csrfCookies = resp.Cookies()
 			log.Debug().Msgf("Received CSRF Token - %v", c.token)
+			// Cache the token so that it is reused by subsequent modifying calls
+			c.exe.SetCsrfToken(c.token, c.csrfCookies)
 		} else {
 			_, err = c.exe.LogError(resp, "Get CSRF Token")
 			return "", nil, err
@@ -46,7 +53,7 @@ func InitHeadersAndCookies(exe *httpclnt.HTTPExecuter) (headers map[string]strin
 	headers = map[string]string{}
 	cookies = []*http.Cookie{}
 
-	if exe.AuthType == "BASIC" {
+	if csrfRequired(exe) {
 		csrf := NewCsrf(exe)
 		var token string
 		token, cookies, err = csrf.GetToken()
@@ -54,3 +61,24 @@ func InitHeadersAndCookies(exe *httpclnt.HTTPExecuter) (headers map[string]strin
 	}
 	return
 }
+
+func isCsrfTokenValidationFailure(resp *http.Response, exe *httpclnt.HTTPExecuter) bool {
+	if !csrfRequired(exe) || resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if strings.EqualFold(resp.Header.Get("x-csrf-token"), "Required") {
+		return true
+	}
+	// Check the response body, and restore it so that it is still available for error logging
+	resBody, err := exe.ReadRespBody(resp)
+	if err != nil {
+		return false
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(resBody))
+	return strings.Contains(string(resBody), "CSRF token validation failed")
+}
+
+// csrfRequired returns true if modifying calls need a CSRF token, which is not the case for calls with an OAuth token
+func csrfRequired(exe *httpclnt.HTTPExecuter) bool {
+	return exe.AuthType == "BASIC" || exe.AuthType == "CERTIFICATE"
+}
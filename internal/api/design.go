@@ -18,8 +18,9 @@ type DesigntimeArtifact interface {
 	Delete(id string) error
 	Get(id string, version string) (string, string, bool, error)
 	Download(targetFile string, id string) error
+	DownloadVersion(targetFile string, id string, version string) error
 	CopyContent(srcDir string, tgtDir string) error
-	CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) (bool, error)
+	CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) (*file.DiffResult, error)
 }
 
 type designtimeArtifactData struct {
@@ -73,9 +74,9 @@ func constructUpdateBody(method string, id string, name string, packageId string
 	return requestBody, nil
 }
 
-func download(targetFile string, id string, artifactType string, exe *httpclnt.HTTPExecuter) error {
+func download(targetFile string, id string, version string, artifactType string, exe *httpclnt.HTTPExecuter) error {
 	log.Info().Msgf("Getting content of artifact %v from tenant for comparison", id)
-	content, err := getContent(id, "active", artifactType, exe)
+	content, err := getContent(id, version, artifactType, exe)
 	if err != nil {
 		return err
 	}
@@ -109,7 +110,8 @@ func update(id string, name string, packageId string, artifactDir string, artifa
 func deploy(id string, artifactType string, exe *httpclnt.HTTPExecuter) error {
 	log.Info().Msgf("Deploying %v designtime artifact %v", artifactType, id)
 	urlPath := fmt.Sprintf("/api/v1/Deploy%vDesigntimeArtifact?Id='%s'&Version='active'", artifactType, id)
-	return modifyingCall("POST", urlPath, nil, 202, fmt.Sprintf("Deploy %v designtime artifact", artifactType), exe)
+	// Triggering deployment again has the same outcome, so it is safe to retry
+	return retryableModifyingCall("POST", urlPath, nil, 202, fmt.Sprintf("Deploy %v designtime artifact", artifactType), exe)
 }
 
 func deleteCall(id string, artifactType string, exe *httpclnt.HTTPExecuter) error {
@@ -140,7 +142,7 @@ func get(id string, version string, artifactType string, exe *httpclnt.HTTPExecu
 	callType := fmt.Sprintf("Get %v designtime artifact", artifactType)
 	resp, err := readOnlyCall(urlPath, callType, exe)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("%v call failed with response code = 404", callType) {
+		if errors.Is(err, httpclnt.ErrNotFound) {
 			return "", "", false, nil
 		} else {
 			return "", "", false, err
@@ -172,15 +174,16 @@ func getContent(id string, version string, artifactType string, exe *httpclnt.HT
 	return exe.ReadRespBody(resp)
 }
 
-func diffContent(firstDir string, secondDir string) bool {
+func diffContent(firstDir string, secondDir string) *file.DiffResult {
+	result := &file.DiffResult{FirstPath: firstDir, SecondPath: secondDir}
 	log.Info().Msg("Checking for changes in META-INF directory")
-	metaDiffer := file.DiffDirectories(firstDir+"/META-INF", secondDir+"/META-INF")
+	result.Merge(file.DiffDirectories(firstDir+"/META-INF", secondDir+"/META-INF"))
 	log.Info().Msg("Checking for changes in src/main/resources directory")
-	resourcesDiffer := file.DiffDirectories(firstDir+"/src/main/resources", secondDir+"/src/main/resources")
+	result.Merge(file.DiffDirectories(firstDir+"/src/main/resources", secondDir+"/src/main/resources"))
 	log.Info().Msg("Checking for changes in metainfo.prop")
-	metainfoDiffer := DiffOptionalFile(firstDir, secondDir, "metainfo.prop")
+	result.Merge(DiffOptionalFile(firstDir, secondDir, "metainfo.prop"))
 
-	return metaDiffer || resourcesDiffer || metainfoDiffer
+	return result
 }
 
 func copyContent(srcDir string, tgtDir string) error {
@@ -202,15 +205,25 @@ func copyContent(srcDir string, tgtDir string) error {
 	}
 	return nil
 }
-func DiffOptionalFile(srcDir string, tgtDir string, fileRelativePath string) bool {
+func DiffOptionalFile(srcDir string, tgtDir string, fileRelativePath string) *file.DiffResult {
 	downloadedFile := fmt.Sprintf("%v/%v", srcDir, fileRelativePath)
 	gitFile := fmt.Sprintf("%v/%v", tgtDir, fileRelativePath)
+	result := &file.DiffResult{FirstPath: srcDir, SecondPath: tgtDir}
 	if file.Exists(downloadedFile) && file.Exists(gitFile) {
 		return file.DiffFile(downloadedFile, gitFile)
 	} else if !file.Exists(downloadedFile) && !file.Exists(gitFile) {
 		log.Warn().Msgf("Skipping diff of %v as it does not exist in both source and target", fileRelativePath)
-		return false
+		return result
 	}
 	log.Info().Msgf("File %v does not exist in either source or target", fileRelativePath)
-	return true
+	result.Files = append(result.Files, optionalFileDiff(srcDir, fileRelativePath))
+	return result
+}
+
+// optionalFileDiff returns the difference for a file or directory that only exists in the source or target
+func optionalFileDiff(srcDir string, relativePath string) file.FileDiff {
+	if file.Exists(fmt.Sprintf("%v/%v", srcDir, relativePath)) {
+		return file.FileDiff{Path: relativePath, Change: file.Removed}
+	}
+	return file.FileDiff{Path: relativePath, Change: file.Added}
 }
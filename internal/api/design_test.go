@@ -171,7 +171,7 @@ func compare(id string, dt DesigntimeArtifact, t *testing.T) {
 	if err != nil {
 		t.Fatalf("CompareContent failed with error - %v", err)
 	}
-	assert.True(t, dirDiffer, "Directory contents do not differ")
+	assert.True(t, dirDiffer.HasDifferences(), "Directory contents do not differ")
 
 	// Copy to output folder
 	destinationDir := fmt.Sprintf("../../output/download/%v", id)
@@ -34,30 +34,31 @@ func (int *Integration) Get(id string, version string) (string, string, bool, er
 	return get(id, version, int.typ, int.exe)
 }
 func (int *Integration) Download(targetFile string, id string) error {
-	return download(targetFile, id, int.typ, int.exe)
+	return download(targetFile, id, "active", int.typ, int.exe)
+}
+func (int *Integration) DownloadVersion(targetFile string, id string, version string) error {
+	return download(targetFile, id, version, int.typ, int.exe)
 }
 func (int *Integration) CopyContent(srcDir string, tgtDir string) error {
 	return copyContent(srcDir, tgtDir)
 }
-func (int *Integration) CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) (bool, error) {
+func (int *Integration) CompareContent(srcDir string, tgtDir string, scriptMap []string, target string) (*file.DiffResult, error) {
 	// Update the script collection in IFlow BPMN2 XML of source side before diff comparison
 	err := file.UpdateBPMN(srcDir, scriptMap)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// Diff directories excluding parameters.prop
-	dirDiffer := diffContent(srcDir, tgtDir)
+	result := diffContent(srcDir, tgtDir)
 
 	// Handling for parameters.prop differences
 	// - Any configured value will remain in IFlow even if the IFlow is replaced and the parameter is no longer used
 	// - Therefore diff of parameters.prop may come up with false differences
 	if target == "git" {
 		// When syncing (from tenant to Git), include diff of parameter.prop separately
-		paramDiffer := DiffOptionalFile(srcDir, tgtDir, "src/main/resources/parameters.prop")
-		return dirDiffer || paramDiffer, nil
-	} else {
-		// When uploading (from Git to tenant), API is used to update the configuration parameters separately
-		return dirDiffer, nil
+		result.Merge(DiffOptionalFile(srcDir, tgtDir, "src/main/resources/parameters.prop"))
 	}
+	// When uploading (from Git to tenant), API is used to update the configuration parameters separately
+	return result, nil
 }
@@ -90,5 +90,5 @@ func TestIntegration_DeployMockOauth(t *testing.T) {
 func TestIntegration_diffParam(t *testing.T) {
 	dirDiffer := DiffOptionalFile("../../test/testdata/artifacts/collection/IFlow1", "../../test/testdata/artifacts/update/Integration_Test_IFlow", "src/main/resources/parameters.prop")
 
-	assert.True(t, dirDiffer, "Directory contents do not differ")
+	assert.True(t, dirDiffer.HasDifferences(), "Directory contents do not differ")
 }
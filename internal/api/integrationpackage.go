@@ -99,7 +99,7 @@ func (ip *IntegrationPackage) Get(id string) (packageData *PackageSingleData, re
 	callType := "Get IntegrationPackages by ID"
 	resp, err := readOnlyCall(urlPath, callType, ip.exe)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("%v call failed with response code = 404", callType) {
+		if errors.Is(err, httpclnt.ErrNotFound) {
 			return nil, false, false, nil
 		} else {
 			return nil, false, false, err
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type Keystore struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+// KeystoreEntry is a certificate or key pair in the keystore of the tenant.
+type KeystoreEntry struct {
+	Alias          string    `json:"alias"`
+	Type           string    `json:"type"` // Certificate or KeyPair
+	Owner          string    `json:"owner"`
+	SubjectDN      string    `json:"subjectDN"`
+	IssuerDN       string    `json:"issuerDN"`
+	SerialNumber   string    `json:"serialNumber"`
+	ValidNotBefore time.Time `json:"validNotBefore"`
+	ValidNotAfter  time.Time `json:"validNotAfter"`
+}
+
+type keystoreEntriesData struct {
+	Root struct {
+		Results []struct {
+			Alias          string `json:"Alias"`
+			Type           string `json:"Type"`
+			Owner          string `json:"Owner"`
+			SubjectDN      string `json:"SubjectDN"`
+			IssuerDN       string `json:"IssuerDN"`
+			SerialNumber   string `json:"SerialNumber"`
+			ValidNotBefore string `json:"ValidNotBefore"`
+			ValidNotAfter  string `json:"ValidNotAfter"`
+		} `json:"results"`
+	} `json:"d"`
+}
+
+// NewKeystore returns an initialised Keystore instance.
+func NewKeystore(exe *httpclnt.HTTPExecuter) *Keystore {
+	k := new(Keystore)
+	k.exe = exe
+	return k
+}
+
+// HexAlias returns the alias in hexadecimal encoding, which is used as the key of keystore entries in the API.
+func HexAlias(alias string) string {
+	return hex.EncodeToString([]byte(alias))
+}
+
+// GetAll returns all entries of the tenant keystore.
+func (k *Keystore) GetAll() ([]*KeystoreEntry, error) {
+	log.Info().Msg("Getting all keystore entries")
+	urlPath := "/api/v1/KeystoreEntries"
+
+	callType := "Get keystore entries"
+	resp, err := readOnlyCall(urlPath, callType, k.exe)
+	if err != nil {
+		return nil, err
+	}
+	// Process response to extract keystore entries
+	var jsonData *keystoreEntriesData
+	respBody, err := k.exe.ReadRespBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(respBody, &jsonData)
+	if err != nil {
+		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
+		return nil, errors.Wrap(err, 0)
+	}
+	var entries []*KeystoreEntry
+	for _, result := range jsonData.Root.Results {
+		entries = append(entries, &KeystoreEntry{
+			Alias:          result.Alias,
+			Type:           result.Type,
+			Owner:          result.Owner,
+			SubjectDN:      result.SubjectDN,
+			IssuerDN:       result.IssuerDN,
+			SerialNumber:   result.SerialNumber,
+			ValidNotBefore: parseODataDate(result.ValidNotBefore),
+			ValidNotAfter:  parseODataDate(result.ValidNotAfter),
+		})
+	}
+	return entries, nil
+}
+
+// DownloadCertificate returns the public certificate of the keystore entry.
+func (k *Keystore) DownloadCertificate(alias string) ([]byte, error) {
+	log.Info().Msgf("Getting certificate of keystore entry %v", alias)
+	urlPath := fmt.Sprintf("/api/v1/KeystoreEntries('%v')/Certificate/$value", HexAlias(alias))
+
+	callType := "Download certificate of keystore entry"
+	resp, err := readOnlyCallWithBodyAndAcceptType(urlPath, nil, callType, "application/pkix-cert", k.exe)
+	if err != nil {
+		return nil, err
+	}
+	return k.exe.ReadRespBody(resp)
+}
+
+// UploadCertificate adds the certificate in PEM or DER format to the keystore, or updates the existing entry of the
+// alias.
+func (k *Keystore) UploadCertificate(alias string, content []byte) error {
+	log.Info().Msgf("Uploading certificate to keystore entry %v", alias)
+	urlPath := fmt.Sprintf("/api/v1/CertificateResources('%v')/$value?fingerprintVerified=true&returnKeystoreEntries=false&update=true", HexAlias(alias))
+
+	return modifyingCallWithContentType(http.MethodPut, urlPath, content, "application/octet-stream", 200, "Upload certificate to keystore", k.exe)
+}
+
+// UploadKeyPair adds the key pair in PKCS#12 format to the keystore, or updates the existing entry of the alias. The
+// content and password are never logged.
+func (k *Keystore) UploadKeyPair(alias string, content []byte, password string) error {
+	log.Info().Msgf("Uploading key pair to keystore entry %v", alias)
+	urlPath := fmt.Sprintf("/api/v1/KeyPairResources('%v')/$value?password=%v&returnKeystoreEntries=false&update=true", HexAlias(alias), url.QueryEscape(password))
+
+	return sensitiveModifyingCall(http.MethodPut, urlPath, content, "application/octet-stream", 200, "Upload key pair to keystore", k.exe)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexAlias(t *testing.T) {
+	assert.Equal(t, "7361705f636c6f7564", HexAlias("sap_cloud"))
+}
+
+func TestKeystore_GetAll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/KeystoreEntries", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [ {
+  "Hexalias": "7361705f636c6f7564", "Alias": "sap_cloud", "Type": "Certificate", "Owner": "SAP",
+  "SubjectDN": "CN=SAP Cloud Root CA", "IssuerDN": "CN=SAP Cloud Root CA", "SerialNumber": "1",
+  "ValidNotBefore": "/Date(1577836800000)/", "ValidNotAfter": "/Date(1893456000000)/"
+} ] } }`))
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	entries, err := NewKeystore(exe).GetAll()
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, "sap_cloud", entries[0].Alias)
+		assert.Equal(t, "Certificate", entries[0].Type)
+		assert.Equal(t, "CN=SAP Cloud Root CA", entries[0].SubjectDN)
+		assert.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), entries[0].ValidNotAfter)
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type MessageProcessingLogs struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+// MessageLogFilter restricts the message processing logs that are queried. Empty fields are not used for filtering.
+type MessageLogFilter struct {
+	IntegrationFlowName string
+	Status              string // e.g. FAILED, COMPLETED, RETRY
+	CorrelationId       string
+	From                time.Time // Start of the time window, based on the log end time
+	To                  time.Time // End of the time window, based on the log end time
+	CustomHeaders       map[string]string
+}
+
+// MessageProcessingLog is the log of a message processed by an integration flow.
+type MessageProcessingLog struct {
+	MessageGuid            string            `json:"messageGuid"`
+	CorrelationId          string            `json:"correlationId"`
+	ApplicationMessageId   string            `json:"applicationMessageId,omitempty"`
+	ApplicationMessageType string            `json:"applicationMessageType,omitempty"`
+	IntegrationFlowName    string            `json:"integrationFlowName"`
+	Status                 string            `json:"status"`
+	CustomStatus           string            `json:"customStatus,omitempty"`
+	Sender                 string            `json:"sender,omitempty"`
+	Receiver               string            `json:"receiver,omitempty"`
+	LogStart               time.Time         `json:"logStart"`
+	LogEnd                 time.Time         `json:"logEnd"`
+	CustomHeaders          map[string]string `json:"customHeaders,omitempty"`
+	WebLink                string            `json:"webLink,omitempty"`
+}
+
+type messageProcessingLogsData struct {
+	Root struct {
+		Results []struct {
+			MessageGuid            string `json:"MessageGuid"`
+			CorrelationId          string `json:"CorrelationId"`
+			ApplicationMessageId   string `json:"ApplicationMessageId"`
+			ApplicationMessageType string `json:"ApplicationMessageType"`
+			IntegrationFlowName    string `json:"IntegrationFlowName"`
+			Status                 string `json:"Status"`
+			CustomStatus           string `json:"CustomStatus"`
+			Sender                 string `json:"Sender"`
+			Receiver               string `json:"Receiver"`
+			LogStart               string `json:"LogStart"`
+			LogEnd                 string `json:"LogEnd"`
+			AlternateWebLink       string `json:"AlternateWebLink"`
+			CustomHeaderProperties struct {
+				Results []struct {
+					Name  string `json:"Name"`
+					Value string `json:"Value"`
+				} `json:"results"`
+			} `json:"CustomHeaderProperties"`
+		} `json:"results"`
+	} `json:"d"`
+}
+
+type customHeaderPropertiesData struct {
+	Root struct {
+		Results []struct {
+			Log struct {
+				MessageGuid string `json:"MessageGuid"`
+			} `json:"Log"`
+		} `json:"results"`
+	} `json:"d"`
+}
+
+// NewMessageProcessingLogs returns an initialised MessageProcessingLogs instance.
+func NewMessageProcessingLogs(exe *httpclnt.HTTPExecuter) *MessageProcessingLogs {
+	m := new(MessageProcessingLogs)
+	m.exe = exe
+	return m
+}
+
+// Query returns up to top message processing logs that match the filter, starting from the most recent one and
+// skipping the first skip logs.
+func (m *MessageProcessingLogs) Query(filter MessageLogFilter, skip int, top int) ([]*MessageProcessingLog, error) {
+	var messageGuids []string
+	if len(filter.CustomHeaders) > 0 {
+		var err error
+		messageGuids, err = m.customHeaderMessageGuids(filter.CustomHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if len(messageGuids) == 0 {
+			log.Info().Msg("No messages found with the custom header properties")
+			return nil, nil
+		}
+	}
+
+	log.Info().Msg("Getting message processing logs")
+	urlPath := "/api/v1/MessageProcessingLogs?" + queryOptions(filter, messageGuids, skip, top)
+
+	callType := "Get message processing logs"
+	resp, err := readOnlyCall(urlPath, callType, m.exe)
+	if err != nil {
+		return nil, err
+	}
+	// Process response to extract message processing logs
+	var jsonData *messageProcessingLogsData
+	respBody, err := m.exe.ReadRespBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(respBody, &jsonData)
+	if err != nil {
+		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
+		return nil, errors.Wrap(err, 0)
+	}
+	var logs []*MessageProcessingLog
+	for _, result := range jsonData.Root.Results {
+		mpl := &MessageProcessingLog{
+			MessageGuid:            result.MessageGuid,
+			CorrelationId:          result.CorrelationId,
+			ApplicationMessageId:   result.ApplicationMessageId,
+			ApplicationMessageType: result.ApplicationMessageType,
+			IntegrationFlowName:    result.IntegrationFlowName,
+			Status:                 result.Status,
+			CustomStatus:           result.CustomStatus,
+			Sender:                 result.Sender,
+			Receiver:               result.Receiver,
+			LogStart:               parseODataDate(result.LogStart),
+			LogEnd:                 parseODataDate(result.LogEnd),
+			WebLink:                result.AlternateWebLink,
+		}
+		for _, property := range result.CustomHeaderProperties.Results {
+			if mpl.CustomHeaders == nil {
+				mpl.CustomHeaders = map[string]string{}
+			}
+			mpl.CustomHeaders[property.Name] = property.Value
+		}
+		logs = append(logs, mpl)
+	}
+	return logs, nil
+}
+
+// customHeaderMessageGuids returns the GUIDs of the messages that have all the custom header properties. The custom
+// header properties are a to-many navigation of the message processing logs, which OData V2 cannot filter on, so each
+// property is queried separately and the GUIDs of their logs are intersected.
+func (m *MessageProcessingLogs) customHeaderMessageGuids(customHeaders map[string]string) ([]string, error) {
+	var messageGuids []string
+	for i, name := range slices.Sorted(maps.Keys(customHeaders)) {
+		log.Info().Msgf("Getting messages with custom header property %v", name)
+		condition := fmt.Sprintf("Name eq '%v' and Value eq '%v'", escapeODataString(name), escapeODataString(customHeaders[name]))
+		urlPath := "/api/v1/MessageProcessingLogCustomHeaderProperties?$format=json&$expand=Log&$filter=" + escapeQueryValue(condition)
+
+		callType := "Get message processing log custom header properties"
+		resp, err := readOnlyCall(urlPath, callType, m.exe)
+		if err != nil {
+			return nil, err
+		}
+		var jsonData *customHeaderPropertiesData
+		respBody, err := m.exe.ReadRespBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(respBody, &jsonData)
+		if err != nil {
+			log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
+			return nil, errors.Wrap(err, 0)
+		}
+		var headerGuids []string
+		for _, result := range jsonData.Root.Results {
+			headerGuids = append(headerGuids, result.Log.MessageGuid)
+		}
+		if i == 0 {
+			messageGuids = headerGuids
+		} else {
+			messageGuids = slices.DeleteFunc(messageGuids, func(guid string) bool {
+				return !slices.Contains(headerGuids, guid)
+			})
+		}
+	}
+	slices.Sort(messageGuids)
+	return slices.Compact(messageGuids), nil
+}
+
+// queryOptions returns the OData query options for the filter and paging. If message GUIDs are provided, only the logs
+// of these messages are queried.
+func queryOptions(filter MessageLogFilter, messageGuids []string, skip int, top int) string {
+	var conditions []string
+	if filter.IntegrationFlowName != "" {
+		conditions = append(conditions, fmt.Sprintf("IntegrationFlowName eq '%v'", escapeODataString(filter.IntegrationFlowName)))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("Status eq '%v'", escapeODataString(filter.Status)))
+	}
+	if filter.CorrelationId != "" {
+		conditions = append(conditions, fmt.Sprintf("CorrelationId eq '%v'", escapeODataString(filter.CorrelationId)))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("LogEnd ge datetime'%v'", filter.From.UTC().Format("2006-01-02T15:04:05")))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("LogEnd le datetime'%v'", filter.To.UTC().Format("2006-01-02T15:04:05")))
+	}
+	if len(messageGuids) > 0 {
+		var guidConditions []string
+		for _, guid := range messageGuids {
+			guidConditions = append(guidConditions, fmt.Sprintf("MessageGuid eq '%v'", escapeODataString(guid)))
+		}
+		conditions = append(conditions, "("+strings.Join(guidConditions, " or ")+")")
+	}
+
+	options := []string{
+		"$format=json",
+		"$expand=CustomHeaderProperties",
+		"$orderby=" + escapeQueryValue("LogEnd desc"),
+		fmt.Sprintf("$skip=%d", skip),
+		fmt.Sprintf("$top=%d", top),
+	}
+	if len(conditions) > 0 {
+		options = append(options, "$filter="+escapeQueryValue(strings.Join(conditions, " and ")))
+	}
+	return strings.Join(options, "&")
+}
+
+// escapeODataString escapes single quotes in an OData string literal
+func escapeODataString(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+func escapeQueryValue(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
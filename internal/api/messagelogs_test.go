@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageProcessingLogs_Query(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/MessageProcessingLogCustomHeaderProperties", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Log", r.URL.Query().Get("$expand"))
+		switch r.URL.Query().Get("$filter") {
+		case "Name eq 'Customer' and Value eq 'ACME'":
+			w.Write([]byte(`{ "d": { "results": [ { "Name": "Customer", "Value": "ACME", "Log": { "MessageGuid": "AGW1" } },
+  { "Name": "Customer", "Value": "ACME", "Log": { "MessageGuid": "AGW3" } } ] } }`))
+		case "Name eq 'OrderId' and Value eq '4711'":
+			w.Write([]byte(`{ "d": { "results": [ { "Name": "OrderId", "Value": "4711", "Log": { "MessageGuid": "AGW1" } },
+  { "Name": "OrderId", "Value": "4711", "Log": { "MessageGuid": "AGW2" } } ] } }`))
+		default:
+			w.Write([]byte(`{ "d": { "results": [] } }`))
+		}
+	})
+	mux.HandleFunc("/api/v1/MessageProcessingLogs", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("$filter")
+		assert.Equal(t, "20", r.URL.Query().Get("$skip"))
+		assert.Equal(t, "10", r.URL.Query().Get("$top"))
+		w.Write([]byte(`{ "d": { "results": [ {
+  "MessageGuid": "AGW1", "CorrelationId": "AGW2", "IntegrationFlowName": "IFlow1", "Status": "FAILED",
+  "LogStart": "/Date(1706695200000)/", "LogEnd": "/Date(1706695201000)/",
+  "CustomHeaderProperties": { "results": [ { "Name": "OrderId", "Value": "4711" }, { "Name": "Customer", "Value": "ACME" } ] }
+} ] } }`))
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	filter := MessageLogFilter{
+		IntegrationFlowName: "IFlow1",
+		Status:              "FAILED",
+		From:                time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC),
+		CustomHeaders:       map[string]string{"OrderId": "4711", "Customer": "ACME"},
+	}
+	logs, err := NewMessageProcessingLogs(exe).Query(filter, 20, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "IntegrationFlowName eq 'IFlow1' and Status eq 'FAILED' and LogEnd ge datetime'2024-01-31T08:00:00' and (MessageGuid eq 'AGW1')", query)
+	if assert.Equal(t, 1, len(logs), "Expected number of logs = 1") {
+		assert.Equal(t, "AGW1", logs[0].MessageGuid)
+		assert.Equal(t, time.UnixMilli(1706695201000).UTC(), logs[0].LogEnd)
+		assert.Equal(t, map[string]string{"OrderId": "4711", "Customer": "ACME"}, logs[0].CustomHeaders)
+	}
+
+	// No logs are queried if no message has all the custom header properties
+	query = ""
+	filter.CustomHeaders = map[string]string{"OrderId": "4711", "Customer": "Other"}
+	logs, err = NewMessageProcessingLogs(exe).Query(filter, 20, 10)
+
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+	assert.Empty(t, query, "Message processing logs should not be queried")
+}
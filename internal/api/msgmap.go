@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/httpclnt"
 )
 
@@ -33,12 +34,15 @@ func (mm *MessageMapping) Get(id string, version string) (string, string, bool,
 	return get(id, version, mm.typ, mm.exe)
 }
 func (mm *MessageMapping) Download(targetFile string, id string) error {
-	return download(targetFile, id, mm.typ, mm.exe)
+	return download(targetFile, id, "active", mm.typ, mm.exe)
+}
+func (mm *MessageMapping) DownloadVersion(targetFile string, id string, version string) error {
+	return download(targetFile, id, version, mm.typ, mm.exe)
 }
 func (mm *MessageMapping) CopyContent(srcDir string, tgtDir string) error {
 	return copyContent(srcDir, tgtDir)
 }
-func (mm *MessageMapping) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (bool, error) {
+func (mm *MessageMapping) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (*file.DiffResult, error) {
 	// Diff directories
 	return diffContent(srcDir, tgtDir), nil
 }
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type PartnerDirectory struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+// Partner contains all Partner Directory entries of a partner ID.
+type Partner struct {
+	Id                  string                      `json:"-"`
+	StringParameters    map[string]string           `json:"stringParameters"`
+	BinaryParameters    map[string]*BinaryParameter `json:"-"`
+	AlternativePartners []*AlternativePartner       `json:"alternativePartners"`
+	AuthorizedUsers     []string                    `json:"authorizedUsers"`
+}
+
+// BinaryParameter is a binary parameter with its content type, e.g. xml, xsl, json or zip.
+type BinaryParameter struct {
+	ContentType string
+	Value       []byte
+}
+
+// AlternativePartner identifies the partner by the agency, scheme and ID of another identification system.
+type AlternativePartner struct {
+	Agency string `json:"agency"`
+	Scheme string `json:"scheme"`
+	Id     string `json:"id"`
+}
+
+type partnerDirectoryData struct {
+	Root struct {
+		Results []struct {
+			Pid         string `json:"Pid"`
+			Id          string `json:"Id"`
+			Value       string `json:"Value"`
+			ContentType string `json:"ContentType"`
+			Agency      string `json:"Agency"`
+			Scheme      string `json:"Scheme"`
+			User        string `json:"User"`
+		} `json:"results"`
+	} `json:"d"`
+}
+
+// NewPartnerDirectory returns an initialised PartnerDirectory instance.
+func NewPartnerDirectory(exe *httpclnt.HTTPExecuter) *PartnerDirectory {
+	p := new(PartnerDirectory)
+	p.exe = exe
+	return p
+}
+
+// NewPartner returns an empty Partner for the partner ID.
+func NewPartner(pid string) *Partner {
+	return &Partner{
+		Id:                  pid,
+		StringParameters:    map[string]string{},
+		BinaryParameters:    map[string]*BinaryParameter{},
+		AlternativePartners: []*AlternativePartner{},
+		AuthorizedUsers:     []string{},
+	}
+}
+
+// GetAll returns all partners of the Partner Directory, sorted by partner ID.
+func (p *PartnerDirectory) GetAll() ([]*Partner, error) {
+	log.Info().Msg("Getting all Partner Directory entries")
+	partners := map[string]*Partner{}
+	getPartner := func(pid string) *Partner {
+		if _, ok := partners[pid]; !ok {
+			partners[pid] = NewPartner(pid)
+		}
+		return partners[pid]
+	}
+
+	stringParameters, err := p.list("StringParameters")
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range stringParameters.Root.Results {
+		getPartner(result.Pid).StringParameters[result.Id] = result.Value
+	}
+
+	binaryParameters, err := p.list("BinaryParameters")
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range binaryParameters.Root.Results {
+		value, err := base64.StdEncoding.DecodeString(result.Value)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		getPartner(result.Pid).BinaryParameters[result.Id] = &BinaryParameter{ContentType: result.ContentType, Value: value}
+	}
+
+	alternativePartners, err := p.list("AlternativePartners")
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range alternativePartners.Root.Results {
+		partner := getPartner(result.Pid)
+		partner.AlternativePartners = append(partner.AlternativePartners, &AlternativePartner{Agency: result.Agency, Scheme: result.Scheme, Id: result.Id})
+	}
+
+	authorizedUsers, err := p.list("AuthorizedUsers")
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range authorizedUsers.Root.Results {
+		partner := getPartner(result.Pid)
+		partner.AuthorizedUsers = append(partner.AuthorizedUsers, result.User)
+	}
+
+	var sorted []*Partner
+	for _, partner := range partners {
+		partner.Sort()
+		sorted = append(sorted, partner)
+	}
+	slices.SortFunc(sorted, func(a, b *Partner) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return sorted, nil
+}
+
+func (p *PartnerDirectory) list(entityType string) (*partnerDirectoryData, error) {
+	urlPath := fmt.Sprintf("/api/v1/%v", entityType)
+
+	callType := fmt.Sprintf("Get %v", entityType)
+	resp, err := readOnlyCall(urlPath, callType, p.exe)
+	if err != nil {
+		return nil, err
+	}
+	// Process response to extract entries
+	var jsonData *partnerDirectoryData
+	respBody, err := p.exe.ReadRespBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(respBody, &jsonData)
+	if err != nil {
+		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
+		return nil, errors.Wrap(err, 0)
+	}
+	return jsonData, nil
+}
+
+// Sort orders the alternative partners and authorized users so that partners can be compared and stored consistently.
+func (partner *Partner) Sort() {
+	slices.SortFunc(partner.AlternativePartners, func(a, b *AlternativePartner) int {
+		return strings.Compare(a.key(), b.key())
+	})
+	slices.Sort(partner.AuthorizedUsers)
+}
+
+func (ap *AlternativePartner) key() string {
+	return ap.Agency + "|" + ap.Scheme + "|" + ap.Id
+}
+
+// CreateStringParameter creates the string parameter of the partner.
+func (p *PartnerDirectory) CreateStringParameter(pid string, id string, value string) error {
+	log.Info().Msgf("Creating string parameter %v of partner %v", id, pid)
+	requestBody, err := json.Marshal(map[string]string{"Pid": pid, "Id": id, "Value": value})
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return modifyingCall("POST", "/api/v1/StringParameters", requestBody, 201, "Create string parameter", p.exe)
+}
+
+// UpdateStringParameter updates the value of the string parameter of the partner.
+func (p *PartnerDirectory) UpdateStringParameter(pid string, id string, value string) error {
+	log.Info().Msgf("Updating string parameter %v of partner %v", id, pid)
+	urlPath := fmt.Sprintf("/api/v1/StringParameters(Pid='%v',Id='%v')", odataKey(pid), odataKey(id))
+
+	requestBody, err := json.Marshal(map[string]string{"Value": value})
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return modifyingCall("PUT", urlPath, requestBody, 202, "Update string parameter", p.exe)
+}
+
+// DeleteStringParameter deletes the string parameter of the partner.
+func (p *PartnerDirectory) DeleteStringParameter(pid string, id string) error {
+	log.Info().Msgf("Deleting string parameter %v of partner %v", id, pid)
+	urlPath := fmt.Sprintf("/api/v1/StringParameters(Pid='%v',Id='%v')", odataKey(pid), odataKey(id))
+
+	return modifyingCall("DELETE", urlPath, nil, 202, "Delete string parameter", p.exe)
+}
+
+// CreateBinaryParameter creates the binary parameter of the partner.
+func (p *PartnerDirectory) CreateBinaryParameter(pid string, id string, param *BinaryParameter) error {
+	log.Info().Msgf("Creating binary parameter %v of partner %v", id, pid)
+	requestBody, err := json.Marshal(map[string]string{"Pid": pid, "Id": id, "ContentType": param.ContentType, "Value": base64.StdEncoding.EncodeToString(param.Value)})
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return modifyingCall("POST", "/api/v1/BinaryParameters", requestBody, 201, "Create binary parameter", p.exe)
+}
+
+// UpdateBinaryParameter updates the content type and value of the binary parameter of the partner.
+func (p *PartnerDirectory) UpdateBinaryParameter(pid string, id string, param *BinaryParameter) error {
+	log.Info().Msgf("Updating binary parameter %v of partner %v", id, pid)
+	urlPath := fmt.Sprintf("/api/v1/BinaryParameters(Pid='%v',Id='%v')", odataKey(pid), odataKey(id))
+
+	requestBody, err := json.Marshal(map[string]string{"ContentType": param.ContentType, "Value": base64.StdEncoding.EncodeToString(param.Value)})
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return modifyingCall("PUT", urlPath, requestBody, 202, "Update binary parameter", p.exe)
+}
+
+// DeleteBinaryParameter deletes the binary parameter of the partner.
+func (p *PartnerDirectory) DeleteBinaryParameter(pid string, id string) error {
+	log.Info().Msgf("Deleting binary parameter %v of partner %v", id, pid)
+	urlPath := fmt.Sprintf("/api/v1/BinaryParameters(Pid='%v',Id='%v')", odataKey(pid), odataKey(id))
+
+	return modifyingCall("DELETE", urlPath, nil, 202, "Delete binary parameter", p.exe)
+}
+
+// CreateAlternativePartner creates the alternative partner of the partner.
+func (p *PartnerDirectory) CreateAlternativePartner(pid string, ap *AlternativePartner) error {
+	log.Info().Msgf("Creating alternative partner %v/%v/%v of partner %v", ap.Agency, ap.Scheme, ap.Id, pid)
+	requestBody, err := json.Marshal(map[string]string{"Pid": pid, "Agency": ap.Agency, "Scheme": ap.Scheme, "Id": ap.Id})
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return modifyingCall("POST", "/api/v1/AlternativePartners", requestBody, 201, "Create alternative partner", p.exe)
+}
+
+// DeleteAlternativePartner deletes the alternative partner. Its key is the hexadecimal encoding of the agency, scheme
+// and ID.
+func (p *PartnerDirectory) DeleteAlternativePartner(ap *AlternativePartner) error {
+	log.Info().Msgf("Deleting alternative partner %v/%v/%v", ap.Agency, ap.Scheme, ap.Id)
+	urlPath := fmt.Sprintf("/api/v1/AlternativePartners(Hexagency='%v',Hexscheme='%v',Hexid='%v')", HexAlias(ap.Agency), HexAlias(ap.Scheme), HexAlias(ap.Id))
+
+	return modifyingCall("DELETE", urlPath, nil, 202, "Delete alternative partner", p.exe)
+}
+
+// CreateAuthorizedUser authorizes the user for the partner.
+func (p *PartnerDirectory) CreateAuthorizedUser(pid string, user string) error {
+	log.Info().Msgf("Creating authorized user %v of partner %v", user, pid)
+	requestBody, err := json.Marshal(map[string]string{"Pid": pid, "User": user})
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return modifyingCall("POST", "/api/v1/AuthorizedUsers", requestBody, 201, "Create authorized user", p.exe)
+}
+
+// DeleteAuthorizedUser deletes the authorized user.
+func (p *PartnerDirectory) DeleteAuthorizedUser(user string) error {
+	log.Info().Msgf("Deleting authorized user %v", user)
+	urlPath := fmt.Sprintf("/api/v1/AuthorizedUsers('%v')", odataKey(user))
+
+	return modifyingCall("DELETE", urlPath, nil, 202, "Delete authorized user", p.exe)
+}
+
+// DeletePartner deletes the partner with all its entries.
+func (p *PartnerDirectory) DeletePartner(pid string) error {
+	log.Info().Msgf("Deleting partner %v", pid)
+	urlPath := fmt.Sprintf("/api/v1/Partners('%v')", odataKey(pid))
+
+	return modifyingCall("DELETE", urlPath, nil, 202, "Delete partner", p.exe)
+}
+
+// odataKey returns the value escaped for use as a string key in the URL path
+func odataKey(value string) string {
+	return url.PathEscape(escapeODataString(value))
+}
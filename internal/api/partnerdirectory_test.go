@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartnerDirectory_GetAll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/StringParameters", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [
+  { "Pid": "PARTNER_B", "Id": "receiverURL", "Value": "https://b.example.com" },
+  { "Pid": "PARTNER_A", "Id": "receiverURL", "Value": "https://a.example.com" }
+] } }`))
+	})
+	mux.HandleFunc("/api/v1/BinaryParameters", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [ { "Pid": "PARTNER_A", "Id": "mapping", "ContentType": "xsl", "Value": "PHhzbC8+" } ] } }`))
+	})
+	mux.HandleFunc("/api/v1/AlternativePartners", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [ { "Hexagency": "474c4e", "Hexscheme": "444f4d", "Hexid": "31", "Agency": "GLN", "Scheme": "DOM", "Id": "1", "Pid": "PARTNER_A" } ] } }`))
+	})
+	mux.HandleFunc("/api/v1/AuthorizedUsers", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [ { "User": "user2", "Pid": "PARTNER_A" }, { "User": "user1", "Pid": "PARTNER_A" } ] } }`))
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	partners, err := NewPartnerDirectory(exe).GetAll()
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(partners)) {
+		assert.Equal(t, "PARTNER_A", partners[0].Id)
+		assert.Equal(t, map[string]string{"receiverURL": "https://a.example.com"}, partners[0].StringParameters)
+		assert.Equal(t, &BinaryParameter{ContentType: "xsl", Value: []byte("<xsl/>")}, partners[0].BinaryParameters["mapping"])
+		assert.Equal(t, []*AlternativePartner{{Agency: "GLN", Scheme: "DOM", Id: "1"}}, partners[0].AlternativePartners)
+		assert.Equal(t, []string{"user1", "user2"}, partners[0].AuthorizedUsers)
+		assert.Equal(t, "PARTNER_B", partners[1].Id)
+		assert.Empty(t, partners[1].AuthorizedUsers)
+	}
+}
+
+func TestPartnerDirectory_UpdateStringParameter(t *testing.T) {
+	var path string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-csrf-token") == "fetch" {
+			w.Header().Set("x-csrf-token", "dummytoken")
+			return
+		}
+		path = r.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	err := NewPartnerDirectory(exe).UpdateStringParameter("O'Brien Ltd", "url", "https://example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/StringParameters(Pid='O''Brien Ltd',Id='url')", path)
+}
@@ -3,8 +3,11 @@ package api
 import (
 	"encoding/json"
 	"fmt"
-	"io"
+	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/engswee/flashpipe/internal/httpclnt"
 	"github.com/go-errors/errors"
@@ -22,8 +25,87 @@ type runtimeData struct {
 	} `json:"d"`
 }
 
-type runtimeError struct {
-	Parameter []string `json:"parameter"`
+type runtimeArtifactsData struct {
+	Root struct {
+		Results []struct {
+			Id         string `json:"Id"`
+			Version    string `json:"Version"`
+			Name       string `json:"Name"`
+			Type       string `json:"Type"`
+			DeployedBy string `json:"DeployedBy"`
+			DeployedOn string `json:"DeployedOn"`
+			Status     string `json:"Status"`
+		} `json:"results"`
+	} `json:"d"`
+}
+
+// RuntimeArtifact is an artifact that is deployed to the runtime of the tenant.
+type RuntimeArtifact struct {
+	Id         string
+	Version    string
+	Name       string
+	Type       string // e.g. INTEGRATION_FLOW, VALUE_MAPPING
+	DeployedBy string
+	DeployedOn time.Time
+	Status     string
+}
+
+// DesigntimeType returns the designtime artifact type of the runtime artifact as used by NewDesigntimeArtifact, or
+// an empty string if the runtime type has no corresponding designtime artifact type.
+func (a *RuntimeArtifact) DesigntimeType() string {
+	switch a.Type {
+	case "INTEGRATION_FLOW", "REST_API", "SOAP_API", "ODATA_API":
+		return "Integration"
+	case "MESSAGE_MAPPING":
+		return "MessageMapping"
+	case "SCRIPT_COLLECTION":
+		return "ScriptCollection"
+	case "VALUE_MAPPING":
+		return "ValueMapping"
+	default:
+		return ""
+	}
+}
+
+// RuntimeError is the error information of a runtime artifact. Errors of components like adapters are provided as
+// child message instances.
+type RuntimeError struct {
+	Message struct {
+		SubsystemName     string `json:"subsytemName"` // Name is misspelled in the API response
+		SubsystemPartName string `json:"subsystemPartName"`
+		MessageId         string `json:"messageId"`
+	} `json:"message"`
+	Parameter             []string        `json:"parameter"`
+	ChildMessageInstances []*RuntimeError `json:"childMessageInstances"`
+}
+
+// Summary returns the error message of the top level error.
+func (e *RuntimeError) Summary() string {
+	if len(e.Parameter) > 0 {
+		return strings.Join(e.Parameter, "; ")
+	}
+	return e.Message.MessageId
+}
+
+// Causes returns the error messages of the child message instances, each prefixed by its subsystem part (e.g. adapter)
+// and message ID.
+func (e *RuntimeError) Causes() []string {
+	var causes []string
+	for _, child := range e.ChildMessageInstances {
+		var source []string
+		for _, value := range []string{child.Message.SubsystemPartName, child.Message.MessageId} {
+			if value != "" {
+				source = append(source, value)
+			}
+		}
+		cause := child.Summary()
+		if len(source) > 0 && cause != child.Message.MessageId {
+			cause = fmt.Sprintf("[%v] %v", strings.Join(source, " "), cause)
+		}
+		causes = append(causes, cause)
+		causes = append(causes, child.Causes()...)
+	}
+	return causes
 }
 
 // NewRuntime returns an initialised Runtime instance.
@@ -47,19 +129,14 @@ func (r *Runtime) Get(id string) (version string, status string, err error) {
 	callType := "Get runtime artifact"
 	resp, err := readOnlyCall(urlPath, callType, r.exe)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("%v call failed with response code = 404", callType) { // artifact not deployed to runtime
+		if errors.Is(err, httpclnt.ErrNotFound) { // artifact not deployed to runtime
 			return "NOT_DEPLOYED", "", nil
-		} else {
-			bytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return "", "", err
-			}
-			respBody := string(bytes[:])
-			if strings.Contains(respBody, "Requested entity could not be found") { // artifact not deployed to runtime
-				return "NOT_DEPLOYED", "", nil
-			}
-			return "", "", err
 		}
+		var httpErr *httpclnt.HTTPError
+		if errors.As(err, &httpErr) && strings.Contains(string(httpErr.Body), "Requested entity could not be found") { // artifact not deployed to runtime
+			return "NOT_DEPLOYED", "", nil
+		}
+		return "", "", err
 	}
 	// Process response to extract version and status
 	var jsonData *runtimeData
@@ -79,26 +156,104 @@ func (r *Runtime) Get(id string) (version string, status string, err error) {
 	}
 }
 
-func (r *Runtime) GetErrorInfo(id string) (string, error) {
+// GetAll returns all artifacts deployed to the runtime.
+func (r *Runtime) GetAll() ([]*RuntimeArtifact, error) {
+	log.Info().Msg("Getting all runtime artifacts")
+	urlPath := "/api/v1/IntegrationRuntimeArtifacts"
+
+	callType := "Get runtime artifacts"
+	resp, err := readOnlyCall(urlPath, callType, r.exe)
+	if err != nil {
+		return nil, err
+	}
+	// Process response to extract artifact details
+	var jsonData *runtimeArtifactsData
+	respBody, err := r.exe.ReadRespBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(respBody, &jsonData)
+	if err != nil {
+		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
+		return nil, errors.Wrap(err, 0)
+	}
+	var artifacts []*RuntimeArtifact
+	for _, result := range jsonData.Root.Results {
+		artifacts = append(artifacts, &RuntimeArtifact{
+			Id:         result.Id,
+			Version:    result.Version,
+			Name:       result.Name,
+			Type:       result.Type,
+			DeployedBy: result.DeployedBy,
+			DeployedOn: parseODataDate(result.DeployedOn),
+			Status:     result.Status,
+		})
+	}
+	return artifacts, nil
+}
+
+var odataDateRegex = regexp.MustCompile(`^/Date\((\d+)[^)]*\)/$`)
+
+// parseODataDate converts a date in OData V2 JSON format, e.g. /Date(1700000000000)/, to time. Zero time is returned
+// for any other format.
+func parseODataDate(value string) time.Time {
+	matches := odataDateRegex.FindStringSubmatch(value)
+	if matches == nil {
+		return time.Time{}
+	}
+	ms, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// GetErrorInfo returns the error information of a runtime artifact whose deployment failed. The tenant sometimes only
+// provides the error information some time after the deployment failed, and returns 204 No Content in the meantime, so
+// the call is repeated up to maxAttempts times with delay between each attempt. Nil is returned if the error
+// information is still not available after that.
+func (r *Runtime) GetErrorInfo(id string, maxAttempts int, delay time.Duration) (*RuntimeError, error) {
+	for i := 0; i < maxAttempts; i++ {
+		if i > 0 {
+			time.Sleep(delay)
+		}
+		errorInfo, err := r.getErrorInfo(id)
+		if err != nil || errorInfo != nil {
+			return errorInfo, err
+		}
+		log.Info().Msgf("Attempt %d - Error information of runtime artifact %v is not available yet", i+1, id)
+	}
+	log.Warn().Msgf("Error information of runtime artifact %v not available after %d attempts", id, maxAttempts)
+	return nil, nil
+}
+
+func (r *Runtime) getErrorInfo(id string) (*RuntimeError, error) {
 	log.Info().Msgf("Getting error info of runtime artifact %v", id)
 	urlPath := fmt.Sprintf("/api/v1/IntegrationRuntimeArtifacts('%v')/ErrorInformation/$value", id)
 
 	callType := "Get runtime artifact error information"
-	resp, err := readOnlyCall(urlPath, callType, r.exe)
-	// TODO - sometimes the error information is only available after some time, so the API returns 204 No content (instead of 200) in the meantime
+	resp, err := r.exe.ExecRequestWithCookies(http.MethodGet, urlPath, http.NoBody, map[string]string{"Accept": "application/json"}, nil)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		resp.Body.Close()
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		_, err = r.exe.LogError(resp, callType)
+		return nil, err
 	}
 	// Process response to extract error info
-	var jsonData *runtimeError
 	respBody, err := r.exe.ReadRespBody(resp)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	err = json.Unmarshal(respBody, &jsonData)
+	var errorInfo *RuntimeError
+	err = json.Unmarshal(respBody, &errorInfo)
 	if err != nil {
 		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
-		return "", errors.Wrap(err, 0)
+		return nil, errors.Wrap(err, 0)
 	}
-	return jsonData.Parameter[0], nil
+	return errorInfo, nil
 }
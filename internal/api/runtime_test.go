@@ -1,6 +1,8 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/engswee/flashpipe/internal/httpclnt"
 	"github.com/engswee/flashpipe/internal/logger"
 	"github.com/spf13/viper"
@@ -80,12 +82,13 @@ func (suite *RuntimeSuite) TearDownSuite() {
 
 func (suite *RuntimeSuite) TestRuntime_GetErrorInfo() {
 	rt := NewRuntime(suite.exe)
-	time.Sleep(15 * time.Second)
-	errorMessage, err := rt.GetErrorInfo("Integration_Test_Message_Mapping")
+	errorInfo, err := rt.GetErrorInfo("Integration_Test_Message_Mapping", 6, 5*time.Second)
 	if err != nil {
 		suite.T().Fatalf("GetErrorInfo failed with error - %v", err)
 	}
-	assert.Contains(suite.T(), errorMessage, "validation of resource is failed", "errorMessage does not have validation error")
+	if assert.NotNil(suite.T(), errorInfo, "Error information not available") {
+		assert.Contains(suite.T(), errorInfo.Summary(), "validation of resource is failed", "errorMessage does not have validation error")
+	}
 }
 
 func (suite *RuntimeSuite) TestRuntime_Get() {
@@ -146,3 +149,72 @@ func tearDownRuntime(t *testing.T, artifactId string, exe *httpclnt.HTTPExecuter
 		}
 	}
 }
+
+func TestRuntime_GetErrorInfoWaitsForContent(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts('IFlow1')/ErrorInformation/$value", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{
+  "message": { "subsytemName": "Deployment", "subsystemPartName": "IFlow", "messageId": "BUNDLE_START_FAILED" },
+  "parameter": [ "Failed to create route" ],
+  "childMessageInstances": [ {
+    "message": { "subsytemName": "Adapter", "subsystemPartName": "SFTP", "messageId": "AUTH_FAILED" },
+    "parameter": [ "Authentication failed", "host sftp.example.com" ],
+    "childMessageInstances": [ { "message": { "messageId": "KEY_NOT_FOUND" }, "parameter": [] } ]
+  } ]
+}`))
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	errorInfo, err := NewRuntime(exe).GetErrorInfo("IFlow1", 3, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls, "Expected number of calls = 3")
+	if assert.NotNil(t, errorInfo) {
+		assert.Equal(t, "BUNDLE_START_FAILED", errorInfo.Message.MessageId)
+		assert.Equal(t, "Failed to create route", errorInfo.Summary())
+		assert.Equal(t, []string{"[SFTP AUTH_FAILED] Authentication failed; host sftp.example.com", "KEY_NOT_FOUND"}, errorInfo.Causes())
+	}
+}
+
+func TestRuntime_GetErrorInfoNotAvailable(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts('IFlow1')/ErrorInformation/$value", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	errorInfo, err := NewRuntime(exe).GetErrorInfo("IFlow1", 2, 0)
+
+	assert.NoError(t, err)
+	assert.Nil(t, errorInfo)
+}
+
+func TestRuntime_GetAll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{ "d": { "results": [
+  { "Id": "IFlow1", "Version": "1.0.2", "Name": "IFlow 1", "Type": "INTEGRATION_FLOW", "DeployedBy": "user1", "DeployedOn": "/Date(1700000000000)/", "Status": "STARTED" },
+  { "Id": "VM1", "Version": "1.0.0", "Name": "VM 1", "Type": "VALUE_MAPPING", "DeployedBy": "user2", "DeployedOn": "", "Status": "ERROR" }
+] } }`))
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	artifacts, err := NewRuntime(exe).GetAll()
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(artifacts), "Expected number of runtime artifacts = 2") {
+		assert.Equal(t, "IFlow1", artifacts[0].Id)
+		assert.Equal(t, "Integration", artifacts[0].DesigntimeType())
+		assert.Equal(t, time.UnixMilli(1700000000000).UTC(), artifacts[0].DeployedOn)
+		assert.Equal(t, "ValueMapping", artifacts[1].DesigntimeType())
+		assert.Equal(t, "ERROR", artifacts[1].Status)
+		assert.True(t, artifacts[1].DeployedOn.IsZero())
+	}
+}
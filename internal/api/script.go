@@ -36,7 +36,10 @@ func (sc *ScriptCollection) Get(id string, version string) (string, string, bool
 	return get(id, version, sc.typ, sc.exe)
 }
 func (sc *ScriptCollection) Download(targetFile string, id string) error {
-	return download(targetFile, id, sc.typ, sc.exe)
+	return download(targetFile, id, "active", sc.typ, sc.exe)
+}
+func (sc *ScriptCollection) DownloadVersion(targetFile string, id string, version string) error {
+	return download(targetFile, id, version, sc.typ, sc.exe)
 }
 func (sc *ScriptCollection) CopyContent(srcDir string, tgtDir string) error {
 	// Copy META-INF and /src/main/resources separately so that other directories like QA, STG, PRD not copied
@@ -68,19 +71,22 @@ func (sc *ScriptCollection) CopyContent(srcDir string, tgtDir string) error {
 	}
 	return nil
 }
-func (sc *ScriptCollection) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (bool, error) {
-	// Diff directories
-	log.Info().Msg("Checking for changes in META-INF directory")
-	metaDiffer := file.DiffDirectories(srcDir+"/META-INF", tgtDir+"/META-INF")
+func (sc *ScriptCollection) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (*file.DiffResult, error) {
 	// It is technically possible to have an empty script collection
 	if file.Exists(srcDir+"/src/main/resources") && file.Exists(tgtDir+"/src/main/resources") {
-		return metaDiffer || diffContent(srcDir, tgtDir), nil
-	} else if !file.Exists(srcDir+"/src/main/resources") && !file.Exists(tgtDir+"/src/main/resources") {
+		return diffContent(srcDir, tgtDir), nil
+	}
+	// Diff directories
+	result := &file.DiffResult{FirstPath: srcDir, SecondPath: tgtDir}
+	log.Info().Msg("Checking for changes in META-INF directory")
+	result.Merge(file.DiffDirectories(srcDir+"/META-INF", tgtDir+"/META-INF"))
+	if !file.Exists(srcDir+"/src/main/resources") && !file.Exists(tgtDir+"/src/main/resources") {
 		log.Warn().Msg("Skipping diff as /src/main/resources does not exist in both source and target")
 		log.Info().Msg("Checking for changes in metainfo.prop")
-		metainfoDiffer := DiffOptionalFile(srcDir, tgtDir, "metainfo.prop")
-		return metaDiffer || metainfoDiffer, nil
+		result.Merge(DiffOptionalFile(srcDir, tgtDir, "metainfo.prop"))
+		return result, nil
 	}
 	log.Info().Msg("Directory /src/main/resources does not exist in either source or target")
-	return true, nil
+	result.Files = append(result.Files, optionalFileDiff(srcDir, "src/main/resources"))
+	return result, nil
 }
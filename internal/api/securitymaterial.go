@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Types of security material
+const (
+	UserCredentials         = "UserCredentials"
+	OAuth2ClientCredentials = "OAuth2ClientCredentials"
+	SecureParameters        = "SecureParameters"
+)
+
+// SecurityMaterialTypes lists the supported types of security material
+var SecurityMaterialTypes = []string{UserCredentials, OAuth2ClientCredentials, SecureParameters}
+
+// Properties of each type of security material that hold secrets. These are never returned by the API.
+var secretProperties = map[string][]string{
+	UserCredentials:         {"Password"},
+	OAuth2ClientCredentials: {"ClientSecret"},
+	SecureParameters:        {"SecureParam"},
+}
+
+type SecurityMaterial struct {
+	exe *httpclnt.HTTPExecuter
+	typ string
+}
+
+// SecurityMaterialEntry is an entry of security material. Properties are named as in the API, e.g. User and Password
+// of UserCredentials.
+type SecurityMaterialEntry struct {
+	Name       string
+	Properties map[string]string
+}
+
+type securityMaterialData struct {
+	Root struct {
+		Results []map[string]any `json:"results"`
+	} `json:"d"`
+}
+
+// NewSecurityMaterial returns an initialised SecurityMaterial instance for the type of security material.
+func NewSecurityMaterial(typ string, exe *httpclnt.HTTPExecuter) *SecurityMaterial {
+	s := new(SecurityMaterial)
+	s.exe = exe
+	s.typ = typ
+	return s
+}
+
+// IsSecretProperty returns true if the property of the type of security material holds a secret.
+func IsSecretProperty(typ string, property string) bool {
+	return slices.Contains(secretProperties[typ], property)
+}
+
+// GetAll returns all entries of the security material type. Secret properties are not included.
+func (s *SecurityMaterial) GetAll() ([]*SecurityMaterialEntry, error) {
+	log.Info().Msgf("Getting all %v", s.typ)
+	urlPath := fmt.Sprintf("/api/v1/%v", s.typ)
+
+	callType := fmt.Sprintf("Get %v", s.typ)
+	resp, err := readOnlyCall(urlPath, callType, s.exe)
+	if err != nil {
+		return nil, err
+	}
+	// Process response to extract entries
+	var jsonData *securityMaterialData
+	respBody, err := s.exe.ReadRespBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(respBody, &jsonData)
+	if err != nil {
+		log.Error().Msgf("Error unmarshalling response as JSON. Response body = %s", respBody)
+		return nil, errors.Wrap(err, 0)
+	}
+	var entries []*SecurityMaterialEntry
+	for _, result := range jsonData.Root.Results {
+		entry := &SecurityMaterialEntry{Properties: map[string]string{}}
+		for property, value := range result {
+			str, ok := value.(string)
+			if !ok || IsSecretProperty(s.typ, property) {
+				continue
+			}
+			if property == "Name" {
+				entry.Name = str
+			} else {
+				entry.Properties[property] = str
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Exists returns true if the entry exists.
+func (s *SecurityMaterial) Exists(name string) (bool, error) {
+	log.Info().Msgf("Checking existence of %v %v", s.typ, name)
+	urlPath := fmt.Sprintf("/api/v1/%v('%v')", s.typ, name)
+
+	callType := fmt.Sprintf("Get %v", s.typ)
+	resp, err := readOnlyCall(urlPath, callType, s.exe)
+	if err != nil {
+		if errors.Is(err, httpclnt.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
+// Create creates the entry. The request body is not logged as it contains secrets.
+func (s *SecurityMaterial) Create(entry *SecurityMaterialEntry) error {
+	log.Info().Msgf("Creating %v %v", s.typ, entry.Name)
+	urlPath := fmt.Sprintf("/api/v1/%v", s.typ)
+
+	requestBody, err := s.constructBody(entry)
+	if err != nil {
+		return err
+	}
+	return sensitiveModifyingCall("POST", urlPath, requestBody, "application/json", 201, fmt.Sprintf("Create %v", s.typ), s.exe)
+}
+
+// Update updates the entry. The request body is not logged as it contains secrets.
+func (s *SecurityMaterial) Update(entry *SecurityMaterialEntry) error {
+	log.Info().Msgf("Updating %v %v", s.typ, entry.Name)
+	urlPath := fmt.Sprintf("/api/v1/%v('%v')", s.typ, entry.Name)
+
+	requestBody, err := s.constructBody(entry)
+	if err != nil {
+		return err
+	}
+	return sensitiveModifyingCall("PUT", urlPath, requestBody, "application/json", 200, fmt.Sprintf("Update %v", s.typ), s.exe)
+}
+
+// Delete deletes the entry.
+func (s *SecurityMaterial) Delete(name string) error {
+	log.Info().Msgf("Deleting %v %v", s.typ, name)
+	urlPath := fmt.Sprintf("/api/v1/%v('%v')", s.typ, name)
+
+	return modifyingCall("DELETE", urlPath, nil, 200, fmt.Sprintf("Delete %v", s.typ), s.exe)
+}
+
+func (s *SecurityMaterial) constructBody(entry *SecurityMaterialEntry) ([]byte, error) {
+	content := map[string]string{"Name": entry.Name}
+	for property, value := range entry.Properties {
+		content[property] = value
+	}
+	requestBody, err := json.Marshal(content)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return requestBody, nil
+}
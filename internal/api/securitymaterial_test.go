@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityMaterial_GetAll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/UserCredentials", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [ {
+  "__metadata": { "type": "com.sap.hci.api.UserCredential" },
+  "Name": "SFTP_User", "Kind": "default", "Description": "SFTP user", "User": "sftpuser", "Password": "should-not-be-returned",
+  "DeployedBy": "user1", "Status": "DEPLOYED"
+} ] } }`))
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	entries, err := NewSecurityMaterial(UserCredentials, exe).GetAll()
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, "SFTP_User", entries[0].Name)
+		assert.Equal(t, "sftpuser", entries[0].Properties["User"])
+		assert.NotContains(t, entries[0].Properties, "Password")
+		assert.NotContains(t, entries[0].Properties, "__metadata")
+	}
+}
@@ -18,29 +18,55 @@ type ServiceDetails struct {
 	OauthPath         string
 	OauthClientId     string
 	OauthClientSecret string
+	TLSOptions        *httpclnt.TLSOptions
+	RetryPolicy       *httpclnt.RetryPolicy
 }
 
 func GetServiceDetails(cmd *cobra.Command) *ServiceDetails {
-	oauthHost := config.GetString(cmd, "oauth-host")
+	return GetServiceDetailsWithPrefix(cmd, "")
+}
+
+// GetServiceDetailsWithPrefix returns the details of the tenant from the flags with the prefix, e.g. target- for
+// --target-tmn-host. The retry flags are shared by all tenants.
+func GetServiceDetailsWithPrefix(cmd *cobra.Command, prefix string) *ServiceDetails {
+	retryPolicy := httpclnt.NewRetryPolicy(config.GetInt(cmd, "retry-max-attempts"), config.GetInt(cmd, "retry-initial-delay"), config.GetInt(cmd, "retry-max-delay"), config.GetBool(cmd, "retry-jitter"))
+	var tlsOptions *httpclnt.TLSOptions
+	if config.GetString(cmd, prefix+"client-cert") != "" || config.GetString(cmd, prefix+"ca-bundle") != "" {
+		tlsOptions = &httpclnt.TLSOptions{
+			CertFile:     config.GetString(cmd, prefix+"client-cert"),
+			KeyFile:      config.GetString(cmd, prefix+"client-key"),
+			Passphrase:   config.GetString(cmd, prefix+"client-cert-passphrase"),
+			CABundleFile: config.GetString(cmd, prefix+"ca-bundle"),
+		}
+	}
+	oauthHost := config.GetString(cmd, prefix+"oauth-host")
 	if oauthHost == "" {
 		return &ServiceDetails{
-			Host:     config.GetString(cmd, "tmn-host"),
-			Userid:   config.GetString(cmd, "tmn-userid"),
-			Password: config.GetString(cmd, "tmn-password"),
+			Host:        config.GetString(cmd, prefix+"tmn-host"),
+			Userid:      config.GetString(cmd, prefix+"tmn-userid"),
+			Password:    config.GetString(cmd, prefix+"tmn-password"),
+			TLSOptions:  tlsOptions,
+			RetryPolicy: retryPolicy,
 		}
 	} else {
 		return &ServiceDetails{
-			Host:              config.GetString(cmd, "tmn-host"),
+			Host:              config.GetString(cmd, prefix+"tmn-host"),
 			OauthHost:         oauthHost,
-			OauthClientId:     config.GetString(cmd, "oauth-clientid"),
-			OauthClientSecret: config.GetString(cmd, "oauth-clientsecret"),
-			OauthPath:         config.GetString(cmd, "oauth-path"),
+			OauthClientId:     config.GetString(cmd, prefix+"oauth-clientid"),
+			OauthClientSecret: config.GetString(cmd, prefix+"oauth-clientsecret"),
+			OauthPath:         config.GetString(cmd, prefix+"oauth-path"),
+			TLSOptions:        tlsOptions,
+			RetryPolicy:       retryPolicy,
 		}
 	}
 }
 
 func InitHTTPExecuter(serviceDetails *ServiceDetails) *httpclnt.HTTPExecuter {
-	return httpclnt.New(serviceDetails.OauthHost, serviceDetails.OauthPath, serviceDetails.OauthClientId, serviceDetails.OauthClientSecret, serviceDetails.Userid, serviceDetails.Password, serviceDetails.Host, "https", 443, true)
+	exe := httpclnt.NewWithTLS(serviceDetails.OauthHost, serviceDetails.OauthPath, serviceDetails.OauthClientId, serviceDetails.OauthClientSecret, serviceDetails.Userid, serviceDetails.Password, serviceDetails.Host, "https", 443, true, serviceDetails.TLSOptions)
+	if serviceDetails.RetryPolicy != nil {
+		exe.SetRetryPolicy(serviceDetails.RetryPolicy)
+	}
+	return exe
 }
 
 func modifyingCall(method string, urlPath string, content []byte, successCode int, callType string, exe *httpclnt.HTTPExecuter) error {
@@ -48,30 +74,62 @@ func modifyingCall(method string, urlPath string, content []byte, successCode in
 }
 
 func modifyingCallWithContentType(method string, urlPath string, content []byte, contentType string, successCode int, callType string, exe *httpclnt.HTTPExecuter) error {
-	headers, cookies, err := InitHeadersAndCookies(exe)
+	return execModifyingCall(method, urlPath, content, contentType, successCode, callType, false, false, exe)
+}
+
+// retryableModifyingCall is used for modifying calls that are safe to repeat when they fail with transient errors, regardless of the HTTP method
+func retryableModifyingCall(method string, urlPath string, content []byte, successCode int, callType string, exe *httpclnt.HTTPExecuter) error {
+	return execModifyingCall(method, urlPath, content, "application/json", successCode, callType, true, false, exe)
+}
+
+// sensitiveModifyingCall is used for modifying calls whose request body contains secrets, so that it is never logged
+func sensitiveModifyingCall(method string, urlPath string, content []byte, contentType string, successCode int, callType string, exe *httpclnt.HTTPExecuter) error {
+	return execModifyingCall(method, urlPath, content, contentType, successCode, callType, false, true, exe)
+}
+
+func execModifyingCall(method string, urlPath string, content []byte, contentType string, successCode int, callType string, retryable bool, sensitive bool, exe *httpclnt.HTTPExecuter) error {
+	resp, err := sendModifyingRequest(method, urlPath, content, contentType, retryable, sensitive, exe)
 	if err != nil {
 		return err
 	}
+	if isCsrfTokenValidationFailure(resp, exe) {
+		log.Warn().Msg("CSRF token validation failed. Fetching new CSRF token and repeating the call")
+		resp.Body.Close()
+		exe.ClearCsrfToken()
+		resp, err = sendModifyingRequest(method, urlPath, content, contentType, retryable, sensitive, exe)
+		if err != nil {
+			return err
+		}
+	}
+	if resp.StatusCode != successCode {
+		_, err = exe.LogError(resp, callType)
+		return err
+	}
+	return nil
+}
+
+func sendModifyingRequest(method string, urlPath string, content []byte, contentType string, retryable bool, sensitive bool, exe *httpclnt.HTTPExecuter) (*http.Response, error) {
+	headers, cookies, err := InitHeadersAndCookies(exe)
+	if err != nil {
+		return nil, err
+	}
 
 	headers["Accept"] = "application/json"
 	var body io.Reader
 	if len(content) > 0 {
 		headers["Content-Type"] = contentType
-		log.Debug().Msgf("Request body = %s", content)
+		if !sensitive {
+			log.Debug().Msgf("Request body = %s", content)
+		}
 		body = bytes.NewReader(content)
 	} else {
 		body = http.NoBody
 	}
 
-	resp, err := exe.ExecRequestWithCookies(method, urlPath, body, headers, cookies)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != successCode {
-		_, err = exe.LogError(resp, callType)
-		return err
+	if retryable {
+		return exe.ExecRetryableRequestWithCookies(method, urlPath, body, headers, cookies)
 	}
-	return nil
+	return exe.ExecRequestWithCookies(method, urlPath, body, headers, cookies)
 }
 
 func readOnlyCall(urlPath string, callType string, exe *httpclnt.HTTPExecuter) (*http.Response, error) {
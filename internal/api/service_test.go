@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModifyingCallRefreshesCsrfToken(t *testing.T) {
+	tokensIssued := 0
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		tokensIssued++
+		if tokensIssued == 1 {
+			w.Header().Set("x-csrf-token", "expired")
+		} else {
+			w.Header().Set("x-csrf-token", "valid")
+		}
+	})
+	mux.HandleFunc("/api/v1/IntegrationPackages", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("x-csrf-token") != "valid" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("CSRF token validation failed"))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	err := modifyingCall("POST", "/api/v1/IntegrationPackages", []byte(`{}`), 201, "Create integration package", exe)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "Expected call to be repeated once with new CSRF token")
+}
+
+func TestModifyingCallReusesCsrfToken(t *testing.T) {
+	tokensIssued := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		tokensIssued++
+		w.Header().Set("x-csrf-token", "valid")
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session1"})
+	})
+	mux.HandleFunc("/api/v1/IntegrationPackages('Dummy')", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("JSESSIONID")
+		if r.Header.Get("x-csrf-token") != "valid" || err != nil || cookie.Value != "session1" {
+			w.Header().Set("x-csrf-token", "Required")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	for i := 0; i < 3; i++ {
+		err := modifyingCall("PUT", "/api/v1/IntegrationPackages('Dummy')", []byte(`{}`), 202, "Update integration package", exe)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, tokensIssued, "Expected CSRF token to be fetched only once")
+}
@@ -1,8 +1,12 @@
 package api
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
 )
 
@@ -20,15 +24,55 @@ func NewValueMapping(exe *httpclnt.HTTPExecuter) DesigntimeArtifact {
 }
 
 func (vm *ValueMapping) Create(id string, name string, packageId string, artifactDir string) error {
-	return create(id, name, packageId, artifactDir, vm.typ, vm.exe)
+	bundleDir, err := vm.bundleDir(artifactDir)
+	if err != nil {
+		return err
+	}
+	defer vm.cleanBundleDir(bundleDir, artifactDir)
+	return create(id, name, packageId, bundleDir, vm.typ, vm.exe)
 }
 func (vm *ValueMapping) Update(id string, name string, packageId string, artifactDir string) error {
+	bundleDir, err := vm.bundleDir(artifactDir)
+	if err != nil {
+		return err
+	}
+	defer vm.cleanBundleDir(bundleDir, artifactDir)
 	log.Info().Msgf("Update of Value Mapping %v by executing delete followed by create", id)
-	err := deleteCall(id, vm.typ, vm.exe)
+	err = deleteCall(id, vm.typ, vm.exe)
 	if err != nil {
 		return err
 	}
-	return create(id, name, packageId, artifactDir, vm.typ, vm.exe)
+	return create(id, name, packageId, bundleDir, vm.typ, vm.exe)
+}
+
+// bundleDir returns the directory with the content of the bundle. If the value mappings are stored as CSV or YAML,
+// they are converted to value_mapping.xml in a copy of the artifact directory.
+func (vm *ValueMapping) bundleDir(artifactDir string) (string, error) {
+	switch file.ValueMappingFormat(artifactDir) {
+	case file.ValueMappingCSV, file.ValueMappingYAML:
+	default:
+		return artifactDir, nil
+	}
+	bundleDir, err := os.MkdirTemp("", "valuemapping")
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	err = vm.CopyContent(artifactDir, bundleDir)
+	if err != nil {
+		return "", err
+	}
+	log.Info().Msgf("Converting value mappings in %v to value_mapping.xml", artifactDir)
+	err = file.ConvertValueMappings(bundleDir, file.ValueMappingXML)
+	if err != nil {
+		return "", err
+	}
+	return bundleDir, nil
+}
+
+func (vm *ValueMapping) cleanBundleDir(bundleDir string, artifactDir string) {
+	if bundleDir != artifactDir {
+		os.RemoveAll(bundleDir)
+	}
 }
 func (vm *ValueMapping) Deploy(id string) error {
 	return deploy(id, vm.typ, vm.exe)
@@ -40,17 +84,28 @@ func (vm *ValueMapping) Get(id string, version string) (string, string, bool, er
 	return get(id, version, vm.typ, vm.exe)
 }
 func (vm *ValueMapping) Download(targetFile string, id string) error {
-	return download(targetFile, id, vm.typ, vm.exe)
+	return download(targetFile, id, "active", vm.typ, vm.exe)
+}
+func (vm *ValueMapping) DownloadVersion(targetFile string, id string, version string) error {
+	return download(targetFile, id, version, vm.typ, vm.exe)
 }
 func (vm *ValueMapping) CopyContent(srcDir string, tgtDir string) error {
-	// Copy META-INF and value_mapping.xml separately so that other directories like QA, STG, PRD not copied
+	// Copy META-INF and value mapping content separately so that other directories like QA, STG, PRD not copied
 	err := file.ReplaceDir(srcDir+"/META-INF", tgtDir+"/META-INF")
 	if err != nil {
 		return err
 	}
-	err = file.CopyFile(srcDir+"/value_mapping.xml", tgtDir+"/value_mapping.xml")
-	if err != nil {
-		return err
+	// The value mapping content is copied in the format of the source, and removed in the other formats
+	format := file.ValueMappingFormat(srcDir)
+	for _, f := range file.ValueMappingFormats {
+		if f == format {
+			err = file.CopyFile(file.ValueMappingFile(srcDir, f), file.ValueMappingFile(tgtDir, f))
+		} else {
+			err = os.RemoveAll(file.ValueMappingFile(tgtDir, f))
+		}
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
 	}
 	// Copy also metainfo.prop that contains the description if it is available
 	if file.Exists(srcDir + "/metainfo.prop") {
@@ -61,13 +116,27 @@ func (vm *ValueMapping) CopyContent(srcDir string, tgtDir string) error {
 	}
 	return nil
 }
-func (vm *ValueMapping) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (bool, error) {
+func (vm *ValueMapping) CompareContent(srcDir string, tgtDir string, _ []string, _ string) (*file.DiffResult, error) {
 	// Diff directories
+	result := &file.DiffResult{FirstPath: srcDir, SecondPath: tgtDir}
 	log.Info().Msg("Checking for changes in META-INF directory")
-	metaDiffer := file.DiffDirectories(srcDir+"/META-INF", tgtDir+"/META-INF")
-	log.Info().Msg("Checking for changes in value_mapping.xml")
-	xmlDiffer := file.DiffFile(srcDir+"/value_mapping.xml", tgtDir+"/value_mapping.xml")
+	result.Merge(file.DiffDirectories(srcDir+"/META-INF", tgtDir+"/META-INF"))
+	// Value mappings are compared by their entries, so that the format and order of the groups and entries do not matter
+	log.Info().Msg("Checking for changes in value mappings")
+	srcGroups, err := file.ReadValueMappings(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	tgtGroups, err := file.ReadValueMappings(tgtDir)
+	if err != nil {
+		return nil, err
+	}
+	if !file.EqualValueMappings(srcGroups, tgtGroups) {
+		log.Info().Msg("Value mapping entries differ")
+		// The files can be in different formats, so the difference is reported on the file of the source
+		result.Files = append(result.Files, file.FileDiff{Path: filepath.Base(file.ValueMappingFile(srcDir, file.ValueMappingFormat(srcDir))), Change: file.Changed})
+	}
 	// TODO - The API for value mapping does not return metainfo.prop, so we can't compare it
 
-	return metaDiffer || xmlDiffer, nil
+	return result, nil
 }
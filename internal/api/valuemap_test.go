@@ -0,0 +1,67 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeValueMappingArtifact(t *testing.T, dir string, fileName string, content string) {
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "META-INF"), os.ModePerm))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "META-INF", "MANIFEST.MF"), []byte("Bundle-SymbolicName: Plants\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0644))
+}
+
+func TestValueMapping_CompareContentIgnoresFormatAndOrder(t *testing.T) {
+	xmlDir := t.TempDir()
+	writeValueMappingArtifact(t, xmlDir, "value_mapping.xml", `<vm version="2.0">
+<group id="1"><entry><agency>ERP</agency><schema>Plant</schema><value>1000</value></entry><entry><agency>B2B</agency><schema>PlantCode</schema><value>DE01</value></entry></group>
+<group id="2"><entry><agency>ERP</agency><schema>Plant</schema><value>2000</value></entry><entry><agency>B2B</agency><schema>PlantCode</schema><value>US01</value></entry></group>
+</vm>`)
+	csvDir := t.TempDir()
+	writeValueMappingArtifact(t, csvDir, "value_mapping.csv", "ERP,B2B\nPlant,PlantCode\n2000,US01\n1000,DE01\n")
+
+	vm := NewValueMapping(nil)
+
+	differ, err := vm.CompareContent(csvDir, xmlDir, nil, "tenant")
+	assert.NoError(t, err)
+	assert.False(t, differ.HasDifferences())
+
+	writeValueMappingArtifact(t, csvDir, "value_mapping.csv", "ERP,B2B\nPlant,PlantCode\n2000,US02\n1000,DE01\n")
+	differ, err = vm.CompareContent(csvDir, xmlDir, nil, "tenant")
+	assert.NoError(t, err)
+	assert.True(t, differ.HasDifferences())
+	assert.Equal(t, "value_mapping.csv", differ.Files[0].Path)
+}
+
+func TestValueMapping_CopyContentReplacesFormat(t *testing.T) {
+	srcDir := t.TempDir()
+	writeValueMappingArtifact(t, srcDir, "value_mapping.yaml", "- entries:\n    - agency: ERP\n      identifier: Plant\n      value: \"1000\"\n")
+	tgtDir := t.TempDir()
+	writeValueMappingArtifact(t, tgtDir, "value_mapping.xml", "<vm version=\"2.0\"/>")
+
+	err := NewValueMapping(nil).CopyContent(srcDir, tgtDir)
+
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tgtDir, "value_mapping.yaml"))
+	assert.NoFileExists(t, filepath.Join(tgtDir, "value_mapping.xml"))
+}
+
+func TestValueMapping_BundleDir(t *testing.T) {
+	artifactDir := t.TempDir()
+	writeValueMappingArtifact(t, artifactDir, "value_mapping.csv", "ERP,B2B\nPlant,PlantCode\n1000,DE01\n")
+	vm := NewValueMapping(nil).(*ValueMapping)
+
+	bundleDir, err := vm.bundleDir(artifactDir)
+	defer vm.cleanBundleDir(bundleDir, artifactDir)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, artifactDir, bundleDir)
+	assert.FileExists(t, filepath.Join(bundleDir, "META-INF", "MANIFEST.MF"))
+	assert.FileExists(t, filepath.Join(bundleDir, "value_mapping.xml"))
+	assert.NoFileExists(t, filepath.Join(bundleDir, "value_mapping.csv"))
+	// The artifact directory is unchanged
+	assert.FileExists(t, filepath.Join(artifactDir, "value_mapping.csv"))
+}
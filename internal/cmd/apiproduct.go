@@ -51,7 +51,7 @@ tenant and a Git repository.`,
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runSyncAPIProduct(cmd); err != nil {
+			if err = writeReport(cmd, runSyncAPIProduct(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -84,6 +84,8 @@ func runSyncAPIProduct(cmd *cobra.Command) error {
 	commitEmail := config.GetString(cmd, "git-commit-email")
 	skipCommit := config.GetBool(cmd, "git-skip-commit")
 	target := config.GetString(cmd, "target")
+	prune := config.GetBool(cmd, "prune")
+	dryRunPlan := getDryRunPlan(cmd)
 
 	serviceDetails := api.GetServiceDetails(cmd)
 	// Initialise HTTP executer
@@ -91,11 +93,11 @@ func runSyncAPIProduct(cmd *cobra.Command) error {
 
 	syncer := sync.NewSyncer(target, "APIProduct", exe)
 	apiproductWorkDir := fmt.Sprintf("%v/apiproduct", workDir)
-	err = syncer.Exec(sync.Request{WorkDir: apiproductWorkDir, ArtifactsDir: artifactsDir, IncludedIds: includedIds, ExcludedIds: excludedIds})
+	err = syncer.Exec(sync.Request{WorkDir: apiproductWorkDir, ArtifactsDir: artifactsDir, IncludedIds: includedIds, ExcludedIds: excludedIds, Prune: prune, Plan: dryRunPlan})
 	if err != nil {
 		return err
 	}
-	if target == "git" && !skipCommit {
+	if target == "git" && !skipCommit && dryRunPlan == nil {
 		err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
 		if err != nil {
 			return err
@@ -107,5 +109,8 @@ func runSyncAPIProduct(cmd *cobra.Command) error {
 		return errors.Wrap(err, 0)
 	}
 
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
 	return nil
 }
@@ -52,7 +52,7 @@ tenant and a Git repository.`,
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runSyncAPIProxy(cmd); err != nil {
+			if err = writeReport(cmd, runSyncAPIProxy(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -85,6 +85,8 @@ func runSyncAPIProxy(cmd *cobra.Command) error {
 	commitEmail := config.GetString(cmd, "git-commit-email")
 	skipCommit := config.GetBool(cmd, "git-skip-commit")
 	target := config.GetString(cmd, "target")
+	prune := config.GetBool(cmd, "prune")
+	dryRunPlan := getDryRunPlan(cmd)
 
 	serviceDetails := api.GetServiceDetails(cmd)
 	// Initialise HTTP executer
@@ -92,11 +94,11 @@ func runSyncAPIProxy(cmd *cobra.Command) error {
 
 	syncer := sync.NewSyncer(target, "APIProxy", exe)
 	apiproxyWorkDir := fmt.Sprintf("%v/apiproxy", workDir)
-	err = syncer.Exec(sync.Request{WorkDir: apiproxyWorkDir, ArtifactsDir: artifactsDir, IncludedIds: includedIds, ExcludedIds: excludedIds})
+	err = syncer.Exec(sync.Request{WorkDir: apiproxyWorkDir, ArtifactsDir: artifactsDir, IncludedIds: includedIds, ExcludedIds: excludedIds, Prune: prune, Plan: dryRunPlan})
 	if err != nil {
 		return err
 	}
-	if target == "git" && !skipCommit {
+	if target == "git" && !skipCommit && dryRunPlan == nil {
 		err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
 		if err != nil {
 			return err
@@ -108,5 +110,8 @@ func runSyncAPIProxy(cmd *cobra.Command) error {
 		return errors.Wrap(err, 0)
 	}
 
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
 	return nil
 }
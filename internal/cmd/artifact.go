@@ -9,6 +9,7 @@ import (
 	"github.com/engswee/flashpipe/internal/config"
 	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/engswee/flashpipe/internal/sync"
 	"github.com/rs/zerolog/log"
@@ -33,7 +34,7 @@ SAP Integration Suite tenant.`,
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runUpdateArtifact(cmd); err != nil {
+			if err = writeReport(cmd, runUpdateArtifact(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -48,10 +49,12 @@ SAP Integration Suite tenant.`,
 	artifactCmd.Flags().String("package-name", "", "Name of Integration Package. Defaults to package-id value when not provided")
 	artifactCmd.Flags().String("dir-artifact", "", "Directory containing contents of designtime artifact")
 	artifactCmd.Flags().String("file-param", "", "Use a different parameters.prop file instead of the default in src/main/resources/ ")
+	artifactCmd.Flags().String("environment", "", "Environment (e.g. QA, PRD) whose parameters.prop overlay in the artifact directory is applied")
 	artifactCmd.Flags().String("file-manifest", "", "Use a different MANIFEST.MF file instead of the default in META-INF/")
 	artifactCmd.Flags().String("dir-work", "/tmp", "Working directory for in-transit files")
 	artifactCmd.Flags().StringSlice("script-collection-map", nil, "Comma-separated source-target ID pairs for converting script collection references during create/update")
 	artifactCmd.Flags().String("artifact-type", "Integration", "Artifact type. Allowed values: Integration, MessageMapping, ScriptCollection, ValueMapping")
+	artifactCmd.Flags().Bool("dry-run", false, dryRunUsage)
 	// TODO - another flag for replacing value mapping in QAS?
 
 	_ = artifactCmd.MarkFlagRequired("artifact-id")
@@ -85,6 +88,8 @@ func runUpdateArtifact(cmd *cobra.Command) error {
 		return fmt.Errorf("security alert for --dir-work: %w", err)
 	}
 	scriptMap := str.TrimSlice(config.GetStringSlice(cmd, "script-collection-map"))
+	environment := config.GetString(cmd, "environment")
+	dryRunPlan := getDryRunPlan(cmd)
 
 	defaultParamFile := fmt.Sprintf("%v/src/main/resources/parameters.prop", artifactDir)
 	if parametersFile == "" {
@@ -131,21 +136,27 @@ func runUpdateArtifact(cmd *cobra.Command) error {
 	exe := api.InitHTTPExecuter(serviceDetails)
 
 	// Create integration package first if required
-	err = createPackage(packageId, packageName, exe)
+	err = createPackage(packageId, packageName, exe, dryRunPlan)
 	if err != nil {
 		return err
 	}
 
 	synchroniser := sync.New(exe)
+	synchroniser.SetEnvironment(environment)
+	synchroniser.SetDryRun(dryRunPlan)
+	synchroniser.SetReport(getReport(cmd))
 
 	err = synchroniser.SingleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile, scriptMap)
 	if err != nil {
 		return err
 	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
 	return nil
 }
 
-func createPackage(packageId string, packageName string, exe *httpclnt.HTTPExecuter) error {
+func createPackage(packageId string, packageName string, exe *httpclnt.HTTPExecuter, dryRunPlan *plan.Plan) error {
 	// Check if integration package exists
 	ip := api.NewIntegrationPackage(exe)
 	_, _, packageExists, err := ip.Get(packageId)
@@ -153,7 +164,9 @@ func createPackage(packageId string, packageName string, exe *httpclnt.HTTPExecu
 		return err
 	}
 
-	if !packageExists {
+	if !packageExists && dryRunPlan != nil {
+		dryRunPlan.Add(plan.Create, "package", packageId, "tenant", "")
+	} else if !packageExists {
 		jsonData := new(api.PackageSingleData)
 		jsonData.Root.Id = packageId
 		jsonData.Root.Name = packageName
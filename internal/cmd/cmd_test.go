@@ -304,6 +304,7 @@ func TestAPIMCommands(t *testing.T) {
 	syncCmd := NewSyncCommand()
 	syncCmd.AddCommand(NewAPIProxyCommand())
 	syncCmd.AddCommand(NewAPIProductCommand())
+	syncCmd.AddCommand(NewPartnerDirectoryCommand())
 	rootCmd.AddCommand(syncCmd)
 
 	var args []string
@@ -2,12 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/engswee/flashpipe/internal/analytics"
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -27,11 +35,23 @@ runtime of SAP Integration Suite tenant.`,
 			default:
 				return fmt.Errorf("invalid value for --artifact-type = %v", artifactType)
 			}
+			// Validate the parallelism
+			parallelism := config.GetInt(cmd, "parallelism")
+			if parallelism < 1 {
+				return fmt.Errorf("invalid value for --parallelism = %d", parallelism)
+			}
+			// Validate Draft Handling
+			draftHandling := config.GetString(cmd, "draft-handling")
+			switch draftHandling {
+			case "SKIP", "ADD", "ERROR":
+			default:
+				return fmt.Errorf("invalid value for --draft-handling = %v", draftHandling)
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runDeploy(cmd); err != nil {
+			if err = writeReport(cmd, runDeploy(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -41,13 +61,27 @@ runtime of SAP Integration Suite tenant.`,
 
 	// Define cobra flags, the default value has the lowest (least significant) precedence
 	deployCmd.Flags().StringSlice("artifact-ids", nil, "Comma separated list of artifact IDs")
+	deployCmd.Flags().String("package-id", "", "ID of integration package whose artifacts are deployed in dependency order")
+	deployCmd.Flags().String("draft-handling", "SKIP", "Handling when artifact of --package-id is in draft version. Allowed values: SKIP, ADD, ERROR")
+	deployCmd.Flags().String("dir-work", "/tmp", "Working directory for in-transit files")
 	deployCmd.Flags().Int("delay-length", 30, "Delay (in seconds) between each check of artifact deployment status")
 	deployCmd.Flags().Int("max-check-limit", 10, "Max number of times to check for artifact deployment status")
 	// To set to false, use --compare-versions=false
 	deployCmd.Flags().Bool("compare-versions", true, "Perform version comparison of design time against runtime before deployment")
 	deployCmd.Flags().String("artifact-type", "Integration", "Artifact type. Allowed values: Integration, MessageMapping, ScriptCollection, ValueMapping")
+	deployCmd.Flags().Int("parallelism", 1, "Number of artifacts to deploy and check concurrently")
+	deployCmd.Flags().Bool("rollback-on-failure", false, "Redeploy the previously running version of an artifact when its deployment fails")
+	deployCmd.Flags().Int("verify-window", 0, "Duration (in seconds) after deployment to watch message processing logs of the integration flows for failed messages")
+	deployCmd.Flags().String("verify-url", "", "URL of HTTP endpoint of the integration flow that is called after deployment")
+	deployCmd.Flags().String("verify-payload-file", "", "Path to file with payload sent to --verify-url")
+	deployCmd.Flags().Int("verify-status", 200, "Expected HTTP status of the response from --verify-url")
+	deployCmd.Flags().String("verify-userid", "", "User ID for Basic Auth of --verify-url, credentials of tenant are used if not set")
+	deployCmd.Flags().String("verify-password", "", "Password for Basic Auth of --verify-url")
+	deployCmd.Flags().Bool("dry-run", false, dryRunUsage)
 
-	_ = deployCmd.MarkFlagRequired("artifact-ids")
+	deployCmd.MarkFlagsOneRequired("artifact-ids", "package-id")
+	deployCmd.MarkFlagsMutuallyExclusive("artifact-ids", "package-id")
+	deployCmd.MarkFlagsRequiredTogether("verify-userid", "verify-password")
 	return deployCmd
 }
 
@@ -55,25 +89,64 @@ func runDeploy(cmd *cobra.Command) error {
 	serviceDetails := api.GetServiceDetails(cmd)
 
 	artifactType := config.GetString(cmd, "artifact-type")
-	log.Info().Msgf("Executing deploy %v command", artifactType)
+	packageId := config.GetString(cmd, "package-id")
+	if packageId != "" {
+		log.Info().Msgf("Executing deploy command for integration package %v", packageId)
+	} else {
+		log.Info().Msgf("Executing deploy %v command", artifactType)
+	}
 
 	artifactIds := config.GetStringSlice(cmd, "artifact-ids")
+	draftHandling := config.GetString(cmd, "draft-handling")
+	workDir, err := config.GetStringWithEnvExpand(cmd, "dir-work")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-work: %w", err)
+	}
 	delayLength := config.GetInt(cmd, "delay-length")
 	maxCheckLimit := config.GetInt(cmd, "max-check-limit")
 	compareVersions := config.GetBool(cmd, "compare-versions")
+	parallelism := config.GetInt(cmd, "parallelism")
+	dryRunPlan := getDryRunPlan(cmd)
+	var rollbackDir string
+	if config.GetBool(cmd, "rollback-on-failure") {
+		rollbackDir = fmt.Sprintf("%v/rollback", workDir)
+		defer os.RemoveAll(rollbackDir)
+	}
 
-	err := deployArtifacts(artifactIds, artifactType, delayLength, maxCheckLimit, compareVersions, serviceDetails)
+	smokeTest, err := newSmokeTest(config.GetInt(cmd, "verify-window"), delayLength, config.GetString(cmd, "verify-url"), config.GetString(cmd, "verify-payload-file"), config.GetInt(cmd, "verify-status"), config.GetString(cmd, "verify-userid"), config.GetString(cmd, "verify-password"), serviceDetails)
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func deployArtifacts(artifactIds []string, artifactType string, delayLength int, maxCheckLimit int, compareVersions bool, serviceDetails *api.ServiceDetails) error {
 
 	// Initialise HTTP executer
 	exe := api.InitHTTPExecuter(serviceDetails)
 
+	var iflowIds []string
+	if packageId != "" {
+		iflowIds, err = deployPackage(packageId, draftHandling, workDir, delayLength, maxCheckLimit, compareVersions, parallelism, rollbackDir, exe, dryRunPlan, getReport(cmd))
+	} else {
+		err = deployArtifacts(artifactIds, artifactType, delayLength, maxCheckLimit, compareVersions, parallelism, rollbackDir, exe, dryRunPlan, getReport(cmd))
+		if artifactType == "Integration" {
+			iflowIds = str.TrimSlice(artifactIds)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+		return nil
+	}
+	if smokeTest != nil {
+		return verifyDeployment(iflowIds, smokeTest, exe)
+	}
+	return nil
+}
+
+// deployArtifacts deploys the artifacts and checks their deployment status. When rollbackDir is set, the content of the
+// previously running version of each artifact is stored in it, and redeployed if the deployment fails.
+func deployArtifacts(artifactIds []string, artifactType string, delayLength int, maxCheckLimit int, compareVersions bool, parallelism int, rollbackDir string, exe *httpclnt.HTTPExecuter, dryRunPlan *plan.Plan, rep *report.Report) error {
+
 	// Initialise designtime artifact
 	dt := api.NewDesigntimeArtifact(artifactType, exe)
 
@@ -82,68 +155,220 @@ func deployArtifacts(artifactIds []string, artifactType string, delayLength int,
 
 	artifactIds = str.TrimSlice(artifactIds)
 
+	var results []*deployResult
+	defer func() {
+		reportDeployResults(rep, artifactType, results)
+	}()
+
+	if dryRunPlan != nil {
+		// Only determine which artifacts would be deployed, there is no deployment status to check
+		for i, id := range artifactIds {
+			log.Info().Msgf("Processing artifact %d - %v", i+1, id)
+			result := deploySingle(dt, rt, id, compareVersions, "", dryRunPlan)
+			results = append(results, result)
+			if result.err != nil {
+				return result.err
+			}
+		}
+		return nil
+	}
+
+	if parallelism > 1 {
+		results = make([]*deployResult, len(artifactIds))
+		return deployArtifactsConcurrently(dt, rt, artifactIds, delayLength, maxCheckLimit, compareVersions, parallelism, rollbackDir, results)
+	}
+
 	// Loop and deploy each artifact
 	for i, id := range artifactIds {
 		log.Info().Msgf("Processing artifact %d - %v", i+1, id)
-		err := deploySingle(dt, rt, id, compareVersions)
+		result := deploySingle(dt, rt, id, compareVersions, rollbackDir, nil)
+		results = append(results, result)
 		// TODO - PRIO1 write error wrapper - https://go.dev/blog/errors-are-values
-		if err != nil {
-			return err
+		if result.err != nil {
+			return result.err
 		}
 	}
 
 	// Check deployment status of artifacts
-	for i, id := range artifactIds {
-		err := checkDeploymentStatus(rt, delayLength, maxCheckLimit, id)
-		if err != nil {
-			return err
+	for i, result := range results {
+		result.err = checkDeploymentStatus(rt, delayLength, maxCheckLimit, result.id)
+		if result.err != nil {
+			rollbackDeployment(dt, rt, delayLength, maxCheckLimit, result)
+			return result.err
 		}
 		// TODO - PRIO1 write error wrapper - https://go.dev/blog/errors-are-values
 
-		log.Info().Msgf("Artifact %d - %v deployed successfully", i+1, id)
+		log.Info().Msgf("Artifact %d - %v deployed successfully", i+1, result.id)
+	}
+
+	log.Info().Msg("🏆 Artifact(s) deployment completed successfully")
+	return nil
+}
+
+type deployResult struct {
+	id                string
+	designtimeVersion string
+	runtimeVersion    string // Runtime version before the deployment, only determined when versions are compared
+	skipped           bool   // Designtime version is already deployed
+	rollbackFile      string // Content of the previously running version, only stored when rollback is enabled
+	rollback          string // Outcome of the rollback after a failed deployment
+	start             time.Time
+	err               error
+}
+
+// deployArtifactsConcurrently deploys the artifacts and stores the result of each artifact at the same index of results
+func deployArtifactsConcurrently(dt api.DesigntimeArtifact, rt *api.Runtime, artifactIds []string, delayLength int, maxCheckLimit int, compareVersions bool, parallelism int, rollbackDir string, results []*deployResult) error {
+	log.Info().Msgf("Deploying %d artifact(s) with up to %d concurrent worker(s)", len(artifactIds), parallelism)
+
+	// Results are stored by index so that the summary follows the order of the input IDs
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(parallelism, len(artifactIds)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				id := artifactIds[i]
+				log.Info().Msgf("Processing artifact %d - %v", i+1, id)
+				result := deploySingle(dt, rt, id, compareVersions, rollbackDir, nil)
+				if result.err == nil {
+					result.err = checkDeploymentStatus(rt, delayLength, maxCheckLimit, id)
+					if result.err != nil {
+						rollbackDeployment(dt, rt, delayLength, maxCheckLimit, result)
+					}
+				}
+				if result.err != nil {
+					log.Error().Msgf("Artifact %d - %v deployment failed: %v", i+1, id, result.err)
+				} else {
+					log.Info().Msgf("Artifact %d - %v deployed successfully", i+1, id)
+				}
+				results[i] = result
+			}
+		}()
 	}
+	for i := range artifactIds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return summariseDeployResults(results)
+}
 
+func summariseDeployResults(results []*deployResult) error {
+	var failures []string
+	for _, result := range results {
+		if result.err != nil {
+			failures = append(failures, fmt.Sprintf("%v: %v", result.id, result.err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d artifact(s) failed to deploy\n%v", len(failures), len(results), strings.Join(failures, "\n"))
+	}
 	log.Info().Msg("🏆 Artifact(s) deployment completed successfully")
 	return nil
 }
 
-func deploySingle(artifact api.DesigntimeArtifact, runtime *api.Runtime, id string, compareVersions bool) error {
+func reportDeployResults(rep *report.Report, artifactType string, results []*deployResult) {
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		entry := report.Entry{ArtifactType: artifactType, Id: result.id, Target: "tenant", VersionBefore: result.runtimeVersion, VersionAfter: result.designtimeVersion}
+		if result.err != nil {
+			entry.Message = result.rollback
+			var deployErr *deploymentError
+			if errors.As(result.err, &deployErr) && deployErr.details != nil {
+				entry.ErrorDetails = deployErr.details
+			}
+			rep.AddError(result.start, entry, result.err)
+			continue
+		}
+		if result.skipped {
+			entry.Action = report.Skipped
+			entry.Message = "version already deployed"
+		} else {
+			entry.Action = report.Deployed
+		}
+		rep.Add(result.start, entry)
+	}
+}
+
+func deploySingle(artifact api.DesigntimeArtifact, runtime *api.Runtime, id string, compareVersions bool, rollbackDir string, dryRunPlan *plan.Plan) *deployResult {
+	result := &deployResult{id: id, start: time.Now()}
 	designtimeVer, _, exists, err := artifact.Get(id, "active")
 	if err != nil {
-		return err
+		result.err = err
+		return result
 	}
 	if !exists {
-		return fmt.Errorf("Designtime artifact %v does not exist", id)
+		result.err = fmt.Errorf("Designtime artifact %v does not exist", id)
+		return result
 	}
+	result.designtimeVersion = designtimeVer
 
-	if compareVersions {
+	if compareVersions || rollbackDir != "" {
 		runtimeVer, _, err := runtime.Get(id)
 		if err != nil {
-			return err
+			result.err = err
+			return result
 		}
+		result.runtimeVersion = runtimeVer
+	}
+
+	if compareVersions {
+		runtimeVer := result.runtimeVersion
 
 		// Compare designtime version with runtime version to determine if deployment is needed
 		log.Info().Msg("Comparing designtime version with runtime version")
 		log.Debug().Msgf("Designtime version = %s. Runtime version = %s", designtimeVer, runtimeVer)
 		if designtimeVer == runtimeVer {
 			log.Info().Msgf("Artifact %v with version %v already deployed. Skipping runtime deployment", id, runtimeVer)
+			result.skipped = true
+		} else if dryRunPlan != nil {
+			dryRunPlan.Add(plan.Deploy, "artifact", id, "tenant", fmt.Sprintf("version %v replacing runtime version %v", designtimeVer, runtimeVer))
 		} else {
 			log.Info().Msgf("🚀 Artifact previously not deployed, or versions differ. Proceeding to deploy artifact %v with version %v", id, designtimeVer)
-			err = artifact.Deploy(id)
-			if err != nil {
-				return err
+			result.rollbackFile = storeRollbackContent(artifact, id, runtimeVer, rollbackDir)
+			result.err = artifact.Deploy(id)
+			if result.err != nil {
+				return result
 			}
 			log.Info().Msgf("Artifact %v deployment triggered", id)
 		}
+	} else if dryRunPlan != nil {
+		dryRunPlan.Add(plan.Deploy, "artifact", id, "tenant", fmt.Sprintf("version %v", designtimeVer))
 	} else {
 		log.Info().Msgf("🚀 Proceeding to deploy artifact %v with version %v", id, designtimeVer)
-		err = artifact.Deploy(id)
-		if err != nil {
-			return err
+		result.rollbackFile = storeRollbackContent(artifact, id, result.runtimeVersion, rollbackDir)
+		result.err = artifact.Deploy(id)
+		if result.err != nil {
+			return result
 		}
 		log.Info().Msgf("Artifact %v deployment triggered", id)
 	}
-	return nil
+	return result
+}
+
+// deploymentError is the error of a deployment that ended with an error status
+type deploymentError struct {
+	status  string
+	details *api.RuntimeError // nil if the error information is not available
+}
+
+func (e *deploymentError) Error() string {
+	if e.details == nil {
+		return fmt.Sprintf("Artifact deployment unsuccessful, ended with status %s. Error information not available", e.status)
+	}
+	msg := fmt.Sprintf("Artifact deployment unsuccessful, ended with status %s. Error message = %s", e.status, e.details.Summary())
+	if e.details.Message.MessageId != "" {
+		msg = fmt.Sprintf("%s (message ID %s)", msg, e.details.Message.MessageId)
+	}
+	for _, cause := range e.details.Causes() {
+		msg = fmt.Sprintf("%s\n  Caused by: %s", msg, cause)
+	}
+	return msg
 }
 
 func checkDeploymentStatus(runtime *api.Runtime, delayLength int, maxCheckLimit int, id string) error {
@@ -162,13 +387,12 @@ func checkDeploymentStatus(runtime *api.Runtime, delayLength int, maxCheckLimit
 		if status == "STARTED" {
 			return nil
 		} else if status != "STARTING" {
-			// If there is an error, delay before getting the error details as it sometimes return 204 when the error details are not available yet
-			time.Sleep(time.Duration(delayLength) * time.Second)
-			errorMessage, err := runtime.GetErrorInfo(id)
+			// The error details are sometimes only available after some time, so wait for them with the same limits
+			errorInfo, err := runtime.GetErrorInfo(id, maxCheckLimit, time.Duration(delayLength)*time.Second)
 			if err != nil {
 				return err
 			}
-			return fmt.Errorf("Artifact deployment unsuccessful, ended with status %s. Error message = %s", status, errorMessage)
+			return &deploymentError{status: status, details: errorInfo}
 		}
 		if i == (maxCheckLimit - 1) {
 			return fmt.Errorf("Artifact status remained in %s after %d checks", status, maxCheckLimit)
@@ -177,3 +401,58 @@ func checkDeploymentStatus(runtime *api.Runtime, delayLength int, maxCheckLimit
 	}
 	return nil
 }
+
+// storeRollbackContent downloads the designtime content of the running version of the artifact, and returns the path
+// of the downloaded file. An empty path is returned if there is no version to roll back to.
+func storeRollbackContent(artifact api.DesigntimeArtifact, id string, runtimeVersion string, rollbackDir string) string {
+	if rollbackDir == "" {
+		return ""
+	}
+	if runtimeVersion == "" || runtimeVersion == "NOT_DEPLOYED" {
+		log.Warn().Msgf("Artifact %v has no running version to roll back to if the deployment fails", id)
+		return ""
+	}
+	rollbackFile := fmt.Sprintf("%v/%v_%v.zip", rollbackDir, id, runtimeVersion)
+	err := artifact.DownloadVersion(rollbackFile, id, runtimeVersion)
+	if err != nil {
+		log.Warn().Msgf("Content of version %v of artifact %v cannot be downloaded, rollback will not be possible: %v", runtimeVersion, id, err)
+		return ""
+	}
+	return rollbackFile
+}
+
+// rollbackDeployment restores the designtime content of the previously running version of the artifact and redeploys
+// it. The outcome of the rollback is added to the deployment error.
+func rollbackDeployment(artifact api.DesigntimeArtifact, runtime *api.Runtime, delayLength int, maxCheckLimit int, result *deployResult) {
+	if result.rollbackFile == "" {
+		return
+	}
+	log.Warn().Msgf("⏪ Rolling back artifact %v to version %v", result.id, result.runtimeVersion)
+	err := redeployContent(artifact, runtime, delayLength, maxCheckLimit, result.id, result.rollbackFile)
+	if err != nil {
+		log.Error().Msgf("Rollback of artifact %v failed: %v", result.id, err)
+		result.rollback = fmt.Sprintf("rollback to version %v failed", result.runtimeVersion)
+		result.err = fmt.Errorf("%w\nRollback to version %v failed: %v", result.err, result.runtimeVersion, err)
+		return
+	}
+	log.Info().Msgf("Artifact %v rolled back to version %v successfully", result.id, result.runtimeVersion)
+	result.rollback = fmt.Sprintf("rolled back to version %v", result.runtimeVersion)
+	result.err = fmt.Errorf("%w\nRolled back to version %v successfully", result.err, result.runtimeVersion)
+}
+
+func redeployContent(artifact api.DesigntimeArtifact, runtime *api.Runtime, delayLength int, maxCheckLimit int, id string, contentFile string) error {
+	contentDir := strings.TrimSuffix(contentFile, ".zip")
+	err := file.UnzipSource(contentFile, contentDir)
+	if err != nil {
+		return err
+	}
+	err = artifact.Update(id, "", "", contentDir)
+	if err != nil {
+		return err
+	}
+	err = artifact.Deploy(id)
+	if err != nil {
+		return err
+	}
+	return checkDeploymentStatus(runtime, delayLength, maxCheckLimit, id)
+}
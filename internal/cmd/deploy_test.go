@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMockDeployHandler returns the mock HTTP responses of a tenant where the artifacts are deployed with status ERROR
+// if they are in failedIds, or else with status STARTED
+func newMockDeployHandler(failedIds ...string) http.Handler {
+	var mu sync.Mutex
+	deployed := map[string]bool{}
+	failed := map[string]bool{}
+	for _, id := range failedIds {
+		failed[id] = true
+	}
+
+	designtimePath := regexp.MustCompile(`^/api/v1/IntegrationDesigntimeArtifacts\(Id='(.+)',Version='active'\)$`)
+	runtimePath := regexp.MustCompile(`^/api/v1/IntegrationRuntimeArtifacts\('(.+)'\)$`)
+	errorInfoPath := regexp.MustCompile(`^/api/v1/IntegrationRuntimeArtifacts\('(.+)'\)/ErrorInformation/\$value$`)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := r.URL.Path
+		switch {
+		case path == "/api/v1/":
+			w.Header().Set("x-csrf-token", "token123")
+		case path == "/api/v1/DeployIntegrationDesigntimeArtifact":
+			id := r.URL.Query().Get("Id")
+			deployed[id[1:len(id)-1]] = true
+			w.WriteHeader(http.StatusAccepted)
+		case designtimePath.MatchString(path):
+			w.Write([]byte(`{ "d": { "Version": "1.0.1" } }`))
+		case errorInfoPath.MatchString(path):
+			w.Write([]byte(`{ "parameter": [ "Mock deployment error" ] }`))
+		case runtimePath.MatchString(path):
+			id := runtimePath.FindStringSubmatch(path)[1]
+			if !deployed[id] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			status := "STARTED"
+			if failed[id] {
+				status = "ERROR"
+			}
+			w.Write([]byte(fmt.Sprintf(`{ "d": { "Version": "1.0.1", "Status": "%v" } }`, status)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestDeployArtifactsConcurrently(t *testing.T) {
+	exe, _ := httpclnt.NewMockExecuter(t, newMockDeployHandler())
+
+	err := deployArtifacts([]string{"IFlow1", "IFlow2", "IFlow3"}, "Integration", 0, 3, true, 2, "", exe, nil, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestDeployArtifactsConcurrently_FailureSummary(t *testing.T) {
+	exe, _ := httpclnt.NewMockExecuter(t, newMockDeployHandler("IFlow2"))
+
+	err := deployArtifacts([]string{"IFlow1", "IFlow2", "IFlow3"}, "Integration", 0, 3, true, 3, "", exe, nil, nil)
+
+	assert.EqualError(t, err, "1 of 3 artifact(s) failed to deploy\nIFlow2: Artifact deployment unsuccessful, ended with status ERROR. Error message = Mock deployment error")
+}
+
+func TestDeployArtifacts_DryRun(t *testing.T) {
+	exe, _ := httpclnt.NewMockExecuter(t, newMockDeployHandler())
+
+	dryRunPlan := plan.New()
+	err := deployArtifacts([]string{"IFlow1", "IFlow2"}, "Integration", 0, 1, true, 2, "", exe, dryRunPlan, nil)
+
+	assert.NoError(t, err)
+	steps := dryRunPlan.Steps()
+	assert.Equal(t, 2, len(steps), "Expected number of planned deployments = 2")
+	assert.Equal(t, plan.Deploy, steps[0].Action)
+	assert.Equal(t, "IFlow1", steps[0].Id)
+
+	version, _, err := api.NewRuntime(exe).Get("IFlow1")
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT_DEPLOYED", version, "Artifact should not be deployed in dry run")
+}
+
+func TestDeployArtifacts_Report(t *testing.T) {
+	exe, _ := httpclnt.NewMockExecuter(t, newMockDeployHandler("IFlow2"))
+
+	rep := report.New("deploy")
+	err := deployArtifacts([]string{"IFlow1", "IFlow2"}, "Integration", 0, 1, true, 2, "", exe, nil, rep)
+
+	assert.Error(t, err)
+	entries := rep.Entries()
+	if assert.Equal(t, 2, len(entries), "Expected number of report entries = 2") {
+		assert.Equal(t, report.Deployed, entries[0].Action)
+		assert.Equal(t, "NOT_DEPLOYED", entries[0].VersionBefore)
+		assert.Equal(t, "1.0.1", entries[0].VersionAfter)
+		assert.Equal(t, report.Failed, entries[1].Action)
+		assert.Contains(t, entries[1].Error, "Mock deployment error")
+	}
+}
+
+func TestDeployArtifacts_Rollback(t *testing.T) {
+	var mu sync.Mutex
+	designtimeVersion := "1.0.1"
+	runtimeVersion, runtimeStatus := "1.0.0", "STARTED"
+
+	var content bytes.Buffer
+	zw := zip.NewWriter(&content)
+	f, err := zw.Create("META-INF/MANIFEST.MF")
+	assert.NoError(t, err)
+	_, err = f.Write([]byte("Bundle-SymbolicName: IFlow1\nBundle-Version: 1.0.0\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	// Set up local server with mock HTTP responses
+	exe, _ := httpclnt.NewMockExecuter(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/":
+			w.Header().Set("x-csrf-token", "token123")
+		case "/api/v1/DeployIntegrationDesigntimeArtifact":
+			// Only the previous version can be started successfully
+			runtimeVersion, runtimeStatus = designtimeVersion, "STARTED"
+			if designtimeVersion != "1.0.0" {
+				runtimeStatus = "ERROR"
+			}
+			w.WriteHeader(http.StatusAccepted)
+		case "/api/v1/IntegrationDesigntimeArtifacts(Id='IFlow1',Version='active')":
+			if r.Method == http.MethodPut {
+				designtimeVersion = "1.0.0"
+				return
+			}
+			w.Write([]byte(fmt.Sprintf(`{ "d": { "Version": "%v" } }`, designtimeVersion)))
+		case "/api/v1/IntegrationDesigntimeArtifacts(Id='IFlow1',Version='1.0.0')/$value":
+			w.Write(content.Bytes())
+		case "/api/v1/IntegrationRuntimeArtifacts('IFlow1')/ErrorInformation/$value":
+			w.Write([]byte(`{ "parameter": [ "Mock deployment error" ] }`))
+		case "/api/v1/IntegrationRuntimeArtifacts('IFlow1')":
+			w.Write([]byte(fmt.Sprintf(`{ "d": { "Version": "%v", "Status": "%v" } }`, runtimeVersion, runtimeStatus)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	rep := report.New("deploy")
+	err = deployArtifacts([]string{"IFlow1"}, "Integration", 0, 3, true, 1, t.TempDir(), exe, nil, rep)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Error message = Mock deployment error")
+		assert.Contains(t, err.Error(), "Rolled back to version 1.0.0 successfully")
+	}
+	version, _, err := api.NewRuntime(exe).Get("IFlow1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", version, "Previous version should be running after rollback")
+	entries := rep.Entries()
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, "rolled back to version 1.0.0", entries[0].Message)
+	}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Order in which artifacts of different types are deployed when they are in the same deployment level
+var deployTypeOrder = []string{"ValueMapping", "ScriptCollection", "MessageMapping", "Integration"}
+
+// deployPackage deploys all artifacts of the integration package. Artifacts are deployed in levels, and all artifacts
+// of a level are started before the next level that depends on them is deployed. The IDs of the deployed integration
+// flows are returned.
+func deployPackage(packageId string, draftHandling string, workDir string, delayLength int, maxCheckLimit int, compareVersions bool, parallelism int, rollbackDir string, exe *httpclnt.HTTPExecuter, dryRunPlan *plan.Plan, rep *report.Report) ([]string, error) {
+	artifacts, err := getDeployableArtifacts(packageId, draftHandling, exe)
+	if err != nil {
+		return nil, err
+	}
+	if len(artifacts) == 0 {
+		log.Warn().Msgf("No artifacts to deploy in integration package %v", packageId)
+		return nil, nil
+	}
+
+	references, err := getIntegrationReferences(artifacts, workDir, exe)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := deploymentLevels(artifacts, artifactDependencies(artifacts, references))
+	if err != nil {
+		return nil, err
+	}
+
+	var iflowIds []string
+
+	for i, level := range levels {
+		for _, artifactType := range deployTypeOrder {
+			var ids []string
+			for _, artifact := range level {
+				if artifact.ArtifactType == artifactType {
+					ids = append(ids, artifact.Id)
+				}
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			log.Info().Msg("---------------------------------------------------------------------------------")
+			log.Info().Msgf("📢 Deploying level %d/%d - %v artifact(s) %v", i+1, len(levels), artifactType, strings.Join(ids, ", "))
+			err = deployArtifacts(ids, artifactType, delayLength, maxCheckLimit, compareVersions, parallelism, rollbackDir, exe, dryRunPlan, rep)
+			if err != nil {
+				return nil, err
+			}
+			if artifactType == "Integration" {
+				iflowIds = append(iflowIds, ids...)
+			}
+		}
+	}
+	log.Info().Msgf("🏆 Deployment of integration package %v completed successfully", packageId)
+	return iflowIds, nil
+}
+
+// getDeployableArtifacts returns the artifacts of the integration package after applying the draft handling
+func getDeployableArtifacts(packageId string, draftHandling string, exe *httpclnt.HTTPExecuter) ([]*api.ArtifactDetails, error) {
+	log.Info().Msgf("Getting artifacts in integration package %v", packageId)
+	artifacts, err := api.NewIntegrationPackage(exe).GetAllArtifacts(packageId)
+	if err != nil {
+		return nil, err
+	}
+	var deployable []*api.ArtifactDetails
+	for _, artifact := range artifacts {
+		if artifact.IsDraft {
+			switch draftHandling {
+			case "SKIP":
+				log.Warn().Msgf("Artifact %v is in draft version, and will be skipped", artifact.Id)
+				continue
+			case "ADD":
+				log.Info().Msgf("Artifact %v is in draft version, and will be deployed", artifact.Id)
+			case "ERROR":
+				return nil, fmt.Errorf("Artifact %v is in draft version. Save Version in Web UI first!", artifact.Id)
+			}
+		}
+		deployable = append(deployable, artifact)
+	}
+	return deployable, nil
+}
+
+// getIntegrationReferences downloads the integration flows and returns their references to other artifacts
+func getIntegrationReferences(artifacts []*api.ArtifactDetails, workDir string, exe *httpclnt.HTTPExecuter) (map[string]*file.BPMNReferences, error) {
+	downloadDir := fmt.Sprintf("%v/deploy", workDir)
+	err := os.MkdirAll(downloadDir, os.ModePerm)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	defer os.RemoveAll(downloadDir)
+
+	references := map[string]*file.BPMNReferences{}
+	for _, artifact := range artifacts {
+		if artifact.ArtifactType != "Integration" {
+			continue
+		}
+		zipFile := fmt.Sprintf("%v/%v.zip", downloadDir, artifact.Id)
+		err = api.NewDesigntimeArtifact(artifact.ArtifactType, exe).Download(zipFile, artifact.Id)
+		if err != nil {
+			return nil, err
+		}
+		artifactDir := fmt.Sprintf("%v/%v", downloadDir, artifact.Id)
+		err = file.UnzipSource(zipFile, artifactDir)
+		if err != nil {
+			return nil, err
+		}
+		bpmnFiles, err := filepath.Glob(fmt.Sprintf("%v/src/main/resources/scenarioflows/integrationflow/*.iflw", artifactDir))
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		refs := new(file.BPMNReferences)
+		for _, bpmnFile := range bpmnFiles {
+			content, err := os.ReadFile(bpmnFile)
+			if err != nil {
+				return nil, errors.Wrap(err, 0)
+			}
+			fileRefs, err := file.GetBPMNReferences(content)
+			if err != nil {
+				return nil, err
+			}
+			refs.ScriptCollections = append(refs.ScriptCollections, fileRefs.ScriptCollections...)
+			refs.ProcessDirectIn = append(refs.ProcessDirectIn, fileRefs.ProcessDirectIn...)
+			refs.ProcessDirectOut = append(refs.ProcessDirectOut, fileRefs.ProcessDirectOut...)
+		}
+		references[artifact.Id] = refs
+	}
+	return references, nil
+}
+
+// artifactDependencies returns the IDs of the artifacts in the package that each artifact depends on:
+// - message mappings and integration flows depend on all value mappings, as these are looked up at runtime
+// - integration flows depend on all message mappings, and on the script collections referenced by scriptBundleId
+// - integration flows that call a ProcessDirect address depend on the integration flows consuming from that address
+func artifactDependencies(artifacts []*api.ArtifactDetails, references map[string]*file.BPMNReferences) map[string][]string {
+	consumers := map[string][]string{}
+	for id, refs := range references {
+		for _, address := range refs.ProcessDirectIn {
+			consumers[address] = append(consumers[address], id)
+		}
+	}
+
+	dependencies := map[string][]string{}
+	addDependency := func(id string, dependencyId string) {
+		if id != dependencyId && !slices.Contains(dependencies[id], dependencyId) {
+			dependencies[id] = append(dependencies[id], dependencyId)
+		}
+	}
+	for _, artifact := range artifacts {
+		if artifact.ArtifactType != "MessageMapping" && artifact.ArtifactType != "Integration" {
+			continue
+		}
+		for _, other := range artifacts {
+			if other.ArtifactType == "ValueMapping" || (artifact.ArtifactType == "Integration" && other.ArtifactType == "MessageMapping") {
+				addDependency(artifact.Id, other.Id)
+			}
+		}
+		refs := references[artifact.Id]
+		if refs == nil {
+			continue
+		}
+		for _, other := range artifacts {
+			if other.ArtifactType == "ScriptCollection" && slices.Contains(refs.ScriptCollections, other.Id) {
+				addDependency(artifact.Id, other.Id)
+			}
+		}
+		for _, address := range refs.ProcessDirectOut {
+			for _, consumerId := range consumers[address] {
+				addDependency(artifact.Id, consumerId)
+			}
+		}
+	}
+	return dependencies
+}
+
+// deploymentLevels sorts the artifacts topologically into levels, where the artifacts of each level only depend on
+// artifacts of previous levels
+func deploymentLevels(artifacts []*api.ArtifactDetails, dependencies map[string][]string) ([][]*api.ArtifactDetails, error) {
+	remaining := slices.Clone(artifacts)
+	deployed := map[string]bool{}
+	var levels [][]*api.ArtifactDetails
+	for len(remaining) > 0 {
+		var level, next []*api.ArtifactDetails
+		for _, artifact := range remaining {
+			ready := true
+			for _, dependencyId := range dependencies[artifact.Id] {
+				if !deployed[dependencyId] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, artifact)
+			} else {
+				next = append(next, artifact)
+			}
+		}
+		if len(level) == 0 {
+			var ids []string
+			for _, artifact := range next {
+				ids = append(ids, artifact.Id)
+			}
+			return nil, fmt.Errorf("Cyclic dependency found between artifacts %v", strings.Join(ids, ", "))
+		}
+		for _, artifact := range level {
+			deployed[artifact.Id] = true
+			if deps := dependencies[artifact.Id]; len(deps) > 0 {
+				log.Debug().Msgf("Artifact %v depends on %v", artifact.Id, strings.Join(deps, ", "))
+			}
+		}
+		levels = append(levels, level)
+		remaining = next
+	}
+	return levels, nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/stretchr/testify/assert"
+)
+
+func levelIds(levels [][]*api.ArtifactDetails) [][]string {
+	var ids [][]string
+	for _, level := range levels {
+		var levelIds []string
+		for _, artifact := range level {
+			levelIds = append(levelIds, artifact.Id)
+		}
+		ids = append(ids, levelIds)
+	}
+	return ids
+}
+
+func TestDeploymentLevels(t *testing.T) {
+	artifacts := []*api.ArtifactDetails{
+		{Id: "Producer", ArtifactType: "Integration"},
+		{Id: "Consumer", ArtifactType: "Integration"},
+		{Id: "Mapping", ArtifactType: "MessageMapping"},
+		{Id: "Scripts", ArtifactType: "ScriptCollection"},
+		{Id: "Codes", ArtifactType: "ValueMapping"},
+	}
+	references := map[string]*file.BPMNReferences{
+		"Producer": {ProcessDirectOut: []string{"/demo/inbound"}},
+		"Consumer": {ScriptCollections: []string{"Scripts"}, ProcessDirectIn: []string{"/demo/inbound"}},
+	}
+
+	levels, err := deploymentLevels(artifacts, artifactDependencies(artifacts, references))
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Scripts", "Codes"}, {"Mapping"}, {"Consumer"}, {"Producer"}}, levelIds(levels))
+}
+
+func TestDeploymentLevels_Cycle(t *testing.T) {
+	artifacts := []*api.ArtifactDetails{
+		{Id: "IFlow1", ArtifactType: "Integration"},
+		{Id: "IFlow2", ArtifactType: "Integration"},
+	}
+	references := map[string]*file.BPMNReferences{
+		"IFlow1": {ProcessDirectIn: []string{"/a"}, ProcessDirectOut: []string{"/b"}},
+		"IFlow2": {ProcessDirectIn: []string{"/b"}, ProcessDirectOut: []string{"/a"}},
+	}
+
+	_, err := deploymentLevels(artifacts, artifactDependencies(artifacts, references))
+
+	assert.EqualError(t, err, "Cyclic dependency found between artifacts IFlow1, IFlow2")
+}
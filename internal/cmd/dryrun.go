@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+const dryRunUsage = "Print a plan of the changes without making them, read and comparison calls are still executed"
+
+// getDryRunPlan returns a new plan when --dry-run is set, otherwise nil so that changes are executed
+func getDryRunPlan(cmd *cobra.Command) *plan.Plan {
+	if !config.GetBool(cmd, "dry-run") {
+		return nil
+	}
+	log.Info().Msg("📢 Dry run - changes will be listed but not executed")
+	getReport(cmd).SetDryRun()
+	return plan.New()
+}
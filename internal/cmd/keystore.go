@@ -0,0 +1,429 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/repo"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewKeystoreCommand() *cobra.Command {
+
+	keystoreCmd := &cobra.Command{
+		Use:   "keystore",
+		Short: "Manage keystore entries",
+		Long: `Manage certificates and key pairs in the keystore of
+SAP Integration Suite tenant.`,
+	}
+	keystoreCmd.AddCommand(newKeystoreListCommand())
+	keystoreCmd.AddCommand(newKeystoreCheckCommand())
+	keystoreCmd.AddCommand(newKeystoreExportCommand())
+	keystoreCmd.AddCommand(newKeystoreUploadCommand())
+	return keystoreCmd
+}
+
+func newKeystoreListCommand() *cobra.Command {
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List keystore entries",
+		Long:  `List the entries of the tenant keystore with their expiry dates.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			output := config.GetString(cmd, "output")
+			switch output {
+			case outputTable, outputJSON:
+			default:
+				return fmt.Errorf("invalid value for --output = %v", output)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runKeystoreList(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	listCmd.Flags().String("output", outputTable, "Output format. Allowed values: table, json")
+	return listCmd
+}
+
+func newKeystoreCheckCommand() *cobra.Command {
+
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check expiry of keystore entries",
+		Long: `Check the expiry of the entries of the tenant keystore, and fail
+when any entry expires within the specified number of days.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runKeystoreCheck(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	checkCmd.Flags().Int("expiry-days", 30, "Fail when any entry expires within this number of days")
+	checkCmd.Flags().StringSlice("aliases-include", nil, "List of included aliases")
+	checkCmd.Flags().StringSlice("aliases-exclude", nil, "List of excluded aliases")
+
+	checkCmd.MarkFlagsMutuallyExclusive("aliases-include", "aliases-exclude")
+	return checkCmd
+}
+
+func newKeystoreExportCommand() *cobra.Command {
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export certificates to Git",
+		Long: `Export the public certificates of the entries of the tenant
+keystore to a Git repository.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runKeystoreExport(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	exportCmd.Flags().String("dir-git-repo", "", "Directory of Git repository")
+	exportCmd.Flags().String("dir-certificates", "", "Directory containing exported certificates (default \"<dir-git-repo>/certificates\")")
+	exportCmd.Flags().StringSlice("aliases-include", nil, "List of included aliases")
+	exportCmd.Flags().StringSlice("aliases-exclude", nil, "List of excluded aliases")
+	exportCmd.Flags().String("git-commit-msg", "Keystore certificates export of "+time.Now().Format(time.UnixDate), "Message used in commit")
+	exportCmd.Flags().String("git-commit-user", "github-actions[bot]", "User used in commit")
+	exportCmd.Flags().String("git-commit-email", "41898282+github-actions[bot]@users.noreply.github.com", "Email used in commit")
+	exportCmd.Flags().Bool("git-skip-commit", false, "Skip committing changes to Git repository")
+	exportCmd.Flags().Bool("dry-run", false, dryRunUsage)
+
+	_ = exportCmd.MarkFlagRequired("dir-git-repo")
+	exportCmd.MarkFlagsMutuallyExclusive("aliases-include", "aliases-exclude")
+	return exportCmd
+}
+
+func newKeystoreUploadCommand() *cobra.Command {
+
+	uploadCmd := &cobra.Command{
+		Use:   "upload",
+		Short: "Upload certificate or key pair",
+		Long: `Upload a certificate or key pair from a file to the keystore of
+SAP Integration Suite tenant. An existing entry with the same alias is updated.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runKeystoreUpload(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	uploadCmd.Flags().String("alias", "", "Alias of keystore entry")
+	uploadCmd.Flags().String("certificate-file", "", "Path to certificate file in PEM or DER format")
+	uploadCmd.Flags().String("keypair-file", "", "Path to key pair file in PKCS#12 format")
+	uploadCmd.Flags().String("keypair-password", "", "Password of the key pair file")
+	uploadCmd.Flags().Bool("dry-run", false, dryRunUsage)
+
+	_ = uploadCmd.MarkFlagRequired("alias")
+	uploadCmd.MarkFlagsOneRequired("certificate-file", "keypair-file")
+	uploadCmd.MarkFlagsMutuallyExclusive("certificate-file", "keypair-file")
+	return uploadCmd
+}
+
+func runKeystoreList(cmd *cobra.Command) error {
+	log.Info().Msg("Executing keystore list command")
+
+	output := config.GetString(cmd, "output")
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	entries, err := getKeystoreEntries(exe, nil, nil)
+	if err != nil {
+		return err
+	}
+	return writeKeystoreEntries(cmd.OutOrStdout(), entries, output, time.Now())
+}
+
+// getKeystoreEntries returns the keystore entries after filtering the aliases, sorted by expiry
+func getKeystoreEntries(exe *httpclnt.HTTPExecuter, includedAliases []string, excludedAliases []string) ([]*api.KeystoreEntry, error) {
+	entries, err := api.NewKeystore(exe).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var filtered []*api.KeystoreEntry
+	for _, entry := range entries {
+		if len(includedAliases) > 0 && !slices.Contains(includedAliases, entry.Alias) {
+			continue
+		}
+		if slices.Contains(excludedAliases, entry.Alias) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	slices.SortStableFunc(filtered, func(a, b *api.KeystoreEntry) int {
+		return a.ValidNotAfter.Compare(b.ValidNotAfter)
+	})
+	return filtered, nil
+}
+
+// daysToExpiry returns the number of whole days from now until the entry expires, which is negative when it has
+// already expired
+func daysToExpiry(entry *api.KeystoreEntry, now time.Time) int {
+	return int(math.Floor(entry.ValidNotAfter.Sub(now).Hours() / 24))
+}
+
+// writeKeystoreEntries writes the keystore entries to w in the output format
+func writeKeystoreEntries(w io.Writer, entries []*api.KeystoreEntry, output string, now time.Time) error {
+	switch output {
+	case outputJSON:
+		if entries == nil {
+			entries = []*api.KeystoreEntry{}
+		}
+		content, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ALIAS\tTYPE\tOWNER\tVALID UNTIL\tDAYS LEFT\tSUBJECT")
+		for _, e := range entries {
+			validUntil, daysLeft := "-", "-"
+			if !e.ValidNotAfter.IsZero() {
+				validUntil = e.ValidNotAfter.Format(time.DateOnly)
+				daysLeft = fmt.Sprintf("%d", daysToExpiry(e, now))
+			}
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n", e.Alias, e.Type, dash(e.Owner), validUntil, daysLeft, dash(e.SubjectDN))
+		}
+		if err := tw.Flush(); err != nil {
+			return errors.Wrap(err, 0)
+		}
+	default:
+		return fmt.Errorf("invalid output format %v", output)
+	}
+	return nil
+}
+
+func runKeystoreCheck(cmd *cobra.Command) error {
+	log.Info().Msg("Executing keystore check command")
+
+	expiryDays := config.GetInt(cmd, "expiry-days")
+	includedAliases := str.TrimSlice(config.GetStringSlice(cmd, "aliases-include"))
+	excludedAliases := str.TrimSlice(config.GetStringSlice(cmd, "aliases-exclude"))
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	entries, err := getKeystoreEntries(exe, includedAliases, excludedAliases)
+	if err != nil {
+		return err
+	}
+	return checkKeystoreExpiry(entries, expiryDays, time.Now(), getReport(cmd))
+}
+
+// checkKeystoreExpiry returns an error listing the entries that expire within the number of days
+func checkKeystoreExpiry(entries []*api.KeystoreEntry, expiryDays int, now time.Time, rep *report.Report) error {
+	var expiring []string
+	for _, entry := range entries {
+		if entry.ValidNotAfter.IsZero() {
+			continue
+		}
+		days := daysToExpiry(entry, now)
+		reportEntry := report.Entry{ArtifactType: "keystore entry", Id: entry.Alias, Target: "tenant", Message: fmt.Sprintf("expires on %v", entry.ValidNotAfter.Format(time.DateOnly))}
+		if days < expiryDays {
+			msg := fmt.Sprintf("%v expires on %v (in %d days)", entry.Alias, entry.ValidNotAfter.Format(time.DateOnly), days)
+			if days < 0 {
+				msg = fmt.Sprintf("%v expired on %v", entry.Alias, entry.ValidNotAfter.Format(time.DateOnly))
+			}
+			log.Error().Msg(msg)
+			expiring = append(expiring, msg)
+			rep.AddError(now, reportEntry, fmt.Errorf("%v", msg))
+			continue
+		}
+		reportEntry.Action = report.Unchanged
+		rep.Add(now, reportEntry)
+	}
+	if len(expiring) > 0 {
+		return fmt.Errorf("%d keystore entry(s) expire within %d days\n%v", len(expiring), expiryDays, strings.Join(expiring, "\n"))
+	}
+	log.Info().Msgf("🏆 No keystore entries expire within %d days", expiryDays)
+	return nil
+}
+
+func runKeystoreExport(cmd *cobra.Command) error {
+	log.Info().Msg("Executing keystore export command")
+
+	gitRepoDir, err := config.GetStringWithEnvExpand(cmd, "dir-git-repo")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-git-repo: %w", err)
+	}
+	certificatesDir, err := config.GetStringWithEnvExpandWithDefault(cmd, "dir-certificates", filepath.Join(gitRepoDir, "certificates"))
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-certificates: %w", err)
+	}
+	includedAliases := str.TrimSlice(config.GetStringSlice(cmd, "aliases-include"))
+	excludedAliases := str.TrimSlice(config.GetStringSlice(cmd, "aliases-exclude"))
+	commitMsg := config.GetString(cmd, "git-commit-msg")
+	commitUser := config.GetString(cmd, "git-commit-user")
+	commitEmail := config.GetString(cmd, "git-commit-email")
+	skipCommit := config.GetBool(cmd, "git-skip-commit")
+	dryRunPlan := getDryRunPlan(cmd)
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	entries, err := getKeystoreEntries(exe, includedAliases, excludedAliases)
+	if err != nil {
+		return err
+	}
+	err = exportCertificates(entries, certificatesDir, api.NewKeystore(exe), dryRunPlan, getReport(cmd))
+	if err != nil {
+		return err
+	}
+	if !skipCommit && dryRunPlan == nil {
+		err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
+		if err != nil {
+			return err
+		}
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
+	return nil
+}
+
+var unsafeFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// exportCertificates writes the certificate of each entry to <alias>.pem in the directory. Files are only written when
+// the certificate has changed.
+func exportCertificates(entries []*api.KeystoreEntry, certificatesDir string, keystore *api.Keystore, dryRunPlan *plan.Plan, rep *report.Report) error {
+	err := os.MkdirAll(certificatesDir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	for _, entry := range entries {
+		start := time.Now()
+		reportEntry := report.Entry{ArtifactType: "certificate", Id: entry.Alias, Target: "git"}
+		content, err := keystore.DownloadCertificate(entry.Alias)
+		if err != nil {
+			rep.AddError(start, reportEntry, err)
+			return err
+		}
+		if !bytes.HasPrefix(bytes.TrimSpace(content), []byte("-----BEGIN")) {
+			// Convert certificate in DER format to PEM
+			content = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: content})
+		}
+		filePath := filepath.Join(certificatesDir, unsafeFileNameRegex.ReplaceAllString(entry.Alias, "_")+".pem")
+		existing, err := os.ReadFile(filePath)
+		switch {
+		case err == nil && bytes.Equal(existing, content):
+			log.Info().Msgf("Certificate of %v is unchanged", entry.Alias)
+			reportEntry.Action = report.Unchanged
+		case dryRunPlan != nil:
+			action, reportAction := plan.Create, report.Created
+			if err == nil {
+				action, reportAction = plan.Update, report.Updated
+			}
+			dryRunPlan.Add(action, "certificate", entry.Alias, "git", filePath)
+			reportEntry.Action = reportAction
+		default:
+			reportEntry.Action = report.Created
+			if err == nil {
+				reportEntry.Action = report.Updated
+			}
+			err = os.WriteFile(filePath, content, 0644)
+			if err != nil {
+				rep.AddError(start, reportEntry, err)
+				return errors.Wrap(err, 0)
+			}
+			log.Info().Msgf("Certificate of %v exported to %v", entry.Alias, filePath)
+		}
+		rep.Add(start, reportEntry)
+	}
+	return nil
+}
+
+func runKeystoreUpload(cmd *cobra.Command) error {
+	log.Info().Msg("Executing keystore upload command")
+
+	alias := config.GetString(cmd, "alias")
+	certificateFile := config.GetString(cmd, "certificate-file")
+	keyPairFile := config.GetString(cmd, "keypair-file")
+	keyPairPassword := config.GetString(cmd, "keypair-password")
+	dryRunPlan := getDryRunPlan(cmd)
+	rep := getReport(cmd)
+
+	start := time.Now()
+	reportEntry := report.Entry{ArtifactType: "keystore entry", Id: alias, Target: "tenant", Action: report.Updated}
+	filePath := certificateFile
+	if keyPairFile != "" {
+		filePath = keyPairFile
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Add(plan.Update, "keystore entry", alias, "tenant", fmt.Sprintf("upload %v", filePath))
+		rep.Add(start, reportEntry)
+		dryRunPlan.Log()
+		return nil
+	}
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	keystore := api.NewKeystore(exe)
+	if keyPairFile != "" {
+		err = keystore.UploadKeyPair(alias, content, keyPairPassword)
+	} else {
+		err = keystore.UploadCertificate(alias, content)
+	}
+	if err != nil {
+		rep.AddError(start, reportEntry, err)
+		return err
+	}
+	rep.Add(start, reportEntry)
+	log.Info().Msgf("🏆 Keystore entry %v uploaded successfully", alias)
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckKeystoreExpiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*api.KeystoreEntry{
+		{Alias: "expired", ValidNotAfter: now.AddDate(0, 0, -1)},
+		{Alias: "expiring", ValidNotAfter: now.AddDate(0, 0, 10)},
+		{Alias: "valid", ValidNotAfter: now.AddDate(1, 0, 0)},
+		{Alias: "unknown"},
+	}
+	rep := report.New("keystore check")
+
+	err := checkKeystoreExpiry(entries, 30, now, rep)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "2 keystore entry(s) expire within 30 days")
+		assert.Contains(t, err.Error(), "expired expired on 2024-12-31")
+		assert.Contains(t, err.Error(), "expiring expires on 2025-01-11 (in 10 days)")
+	}
+	assert.Equal(t, 3, len(rep.Entries()))
+
+	assert.NoError(t, checkKeystoreExpiry(entries[2:], 30, now, report.New("keystore check")))
+}
+
+func TestWriteKeystoreEntries(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*api.KeystoreEntry{{Alias: "sap_cloud", Type: "Certificate", SubjectDN: "CN=SAP", ValidNotAfter: now.AddDate(0, 0, 45)}}
+	var sb strings.Builder
+
+	err := writeKeystoreEntries(&sb, entries, outputTable, now)
+
+	assert.NoError(t, err)
+	assert.Contains(t, sb.String(), "sap_cloud  Certificate  -      2025-02-15   45         CN=SAP")
+}
+
+func TestExportCertificates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/KeystoreEntries('"+api.HexAlias("sap/cloud")+"')/Certificate/$value", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0x30, 0x03, 0x02, 0x01, 0x01})
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+	dir := t.TempDir()
+	entries := []*api.KeystoreEntry{{Alias: "sap/cloud"}}
+
+	// Dry run does not write the file
+	dryRunPlan := plan.New()
+	err := exportCertificates(entries, dir, api.NewKeystore(exe), dryRunPlan, report.New("keystore export"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dryRunPlan.Steps()))
+	assert.NoFileExists(t, filepath.Join(dir, "sap_cloud.pem"))
+
+	rep := report.New("keystore export")
+	err = exportCertificates(entries, dir, api.NewKeystore(exe), nil, rep)
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "sap_cloud.pem"))
+	assert.NoError(t, err)
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----\nMAMCAQE=\n-----END CERTIFICATE-----\n", string(content))
+	assert.Equal(t, report.Created, rep.Entries()[0].Action)
+
+	// Export again leaves the unchanged file as is
+	rep = report.New("keystore export")
+	err = exportCertificates(entries, dir, api.NewKeystore(exe), nil, rep)
+	assert.NoError(t, err)
+	assert.Equal(t, report.Unchanged, rep.Entries()[0].Action)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewLogsCommand() *cobra.Command {
+
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Query message processing logs",
+		Long: `Query the message processing logs of integration flows
+on SAP Integration Suite tenant, starting from the most recent message.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate the output format
+			output := config.GetString(cmd, "output")
+			switch output {
+			case outputTable, outputJSON:
+			default:
+				return fmt.Errorf("invalid value for --output = %v", output)
+			}
+			// Validate paging
+			if config.GetInt(cmd, "top") < 1 {
+				return fmt.Errorf("invalid value for --top = %d", config.GetInt(cmd, "top"))
+			}
+			if config.GetInt(cmd, "skip") < 0 {
+				return fmt.Errorf("invalid value for --skip = %d", config.GetInt(cmd, "skip"))
+			}
+			_, err := getMessageLogFilter(cmd, time.Now())
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runLogs(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	logsCmd.Flags().String("iflow-id", "", "ID of integration flow")
+	logsCmd.Flags().String("status", "", "Status of the messages, e.g. FAILED, COMPLETED, RETRY, ESCALATED")
+	logsCmd.Flags().String("correlation-id", "", "Correlation ID of the messages")
+	logsCmd.Flags().StringSlice("custom-header", nil, "Comma separated list of custom header properties in the format name=value")
+	logsCmd.Flags().String("since", "", "Only messages processed within this duration before now, e.g. 30m, 2h")
+	logsCmd.Flags().String("from", "", "Only messages processed at or after this time, in RFC 3339 format e.g. 2024-01-31T08:00:00Z")
+	logsCmd.Flags().String("to", "", "Only messages processed at or before this time, in RFC 3339 format e.g. 2024-01-31T18:00:00Z")
+	logsCmd.Flags().Int("skip", 0, "Number of messages to skip, for paging through the results")
+	logsCmd.Flags().Int("top", 50, "Max number of messages to return")
+	logsCmd.Flags().String("output", outputTable, "Output format. Allowed values: table, json")
+
+	logsCmd.MarkFlagsMutuallyExclusive("since", "from")
+	return logsCmd
+}
+
+func runLogs(cmd *cobra.Command) error {
+	log.Info().Msg("Executing logs command")
+
+	filter, err := getMessageLogFilter(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+	skip := config.GetInt(cmd, "skip")
+	top := config.GetInt(cmd, "top")
+	output := config.GetString(cmd, "output")
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	logs, err := api.NewMessageProcessingLogs(exe).Query(filter, skip, top)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("%d message processing log(s) found", len(logs))
+	if len(logs) == top {
+		log.Info().Msgf("More messages may be available, use --skip %d to get the next page", skip+top)
+	}
+	return writeMessageLogs(cmd.OutOrStdout(), logs, output)
+}
+
+// getMessageLogFilter returns the filter of the message processing logs from the flags. The time window of --since
+// ends at now.
+func getMessageLogFilter(cmd *cobra.Command, now time.Time) (api.MessageLogFilter, error) {
+	filter := api.MessageLogFilter{
+		IntegrationFlowName: config.GetString(cmd, "iflow-id"),
+		Status:              strings.ToUpper(config.GetString(cmd, "status")),
+		CorrelationId:       config.GetString(cmd, "correlation-id"),
+	}
+	for _, header := range str.TrimSlice(config.GetStringSlice(cmd, "custom-header")) {
+		name, value, found := strings.Cut(header, "=")
+		if !found || name == "" {
+			return filter, fmt.Errorf("invalid value for --custom-header = %v", header)
+		}
+		if filter.CustomHeaders == nil {
+			filter.CustomHeaders = map[string]string{}
+		}
+		filter.CustomHeaders[name] = value
+	}
+	if since := config.GetString(cmd, "since"); since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil || duration <= 0 {
+			return filter, fmt.Errorf("invalid value for --since = %v", since)
+		}
+		filter.From = now.Add(-duration)
+	}
+	if from := config.GetString(cmd, "from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, fmt.Errorf("invalid value for --from = %v", from)
+		}
+		filter.From = t
+	}
+	if to := config.GetString(cmd, "to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, fmt.Errorf("invalid value for --to = %v", to)
+		}
+		filter.To = t
+	}
+	return filter, nil
+}
+
+// writeMessageLogs writes the message processing logs to w in the output format
+func writeMessageLogs(w io.Writer, logs []*api.MessageProcessingLog, output string) error {
+	switch output {
+	case outputJSON:
+		if logs == nil {
+			logs = []*api.MessageProcessingLog{}
+		}
+		content, err := json.MarshalIndent(logs, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "LOG END\tINTEGRATION FLOW\tSTATUS\tMESSAGE GUID\tCORRELATION ID\tAPPLICATION MESSAGE ID")
+		for _, l := range logs {
+			logEnd := ""
+			if !l.LogEnd.IsZero() {
+				logEnd = l.LogEnd.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n", dash(logEnd), l.IntegrationFlowName, l.Status, l.MessageGuid, dash(l.CorrelationId), dash(l.ApplicationMessageId))
+		}
+		if err := tw.Flush(); err != nil {
+			return errors.Wrap(err, 0)
+		}
+	default:
+		return fmt.Errorf("invalid output format %v", output)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMessageLogFilter(t *testing.T) {
+	cmd := NewLogsCommand()
+	_ = cmd.Flags().Set("iflow-id", "IFlow1")
+	_ = cmd.Flags().Set("status", "failed")
+	_ = cmd.Flags().Set("custom-header", "OrderId=4711, Customer=ACME")
+	_ = cmd.Flags().Set("since", "2h")
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	filter, err := getMessageLogFilter(cmd, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "IFlow1", filter.IntegrationFlowName)
+	assert.Equal(t, "FAILED", filter.Status)
+	assert.Equal(t, map[string]string{"OrderId": "4711", "Customer": "ACME"}, filter.CustomHeaders)
+	assert.Equal(t, time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), filter.From)
+	assert.True(t, filter.To.IsZero())
+}
+
+func TestGetMessageLogFilter_InvalidCustomHeader(t *testing.T) {
+	cmd := NewLogsCommand()
+	_ = cmd.Flags().Set("custom-header", "OrderId")
+
+	_, err := getMessageLogFilter(cmd, time.Now())
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid value for --custom-header = OrderId", err.Error())
+	}
+}
@@ -20,7 +20,7 @@ func NewPackageCommand() *cobra.Command {
 SAP Integration Suite tenant.`,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runUpdatePackage(cmd); err != nil {
+			if err = writeReport(cmd, runUpdatePackage(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -30,6 +30,7 @@ SAP Integration Suite tenant.`,
 
 	// Define cobra flags, the default value has the lowest (least significant) precedence
 	packageCmd.Flags().String("package-file", "", "Path to location of package file")
+	packageCmd.Flags().Bool("dry-run", false, dryRunUsage)
 
 	_ = packageCmd.MarkFlagRequired("package-file")
 	return packageCmd
@@ -44,6 +45,14 @@ func runUpdatePackage(cmd *cobra.Command) error {
 	serviceDetails := api.GetServiceDetails(cmd)
 	exe := api.InitHTTPExecuter(serviceDetails)
 	packageSynchroniser := sync.NewSyncer("tenant", "CPIPackage", exe)
+	dryRunPlan := getDryRunPlan(cmd)
 
-	return packageSynchroniser.Exec(sync.Request{PackageFile: packageFile})
+	err := packageSynchroniser.Exec(sync.Request{PackageFile: packageFile, Plan: dryRunPlan, Report: getReport(cmd)})
+	if err != nil {
+		return err
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
+	return nil
 }
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/repo"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewPartnerDirectoryCommand() *cobra.Command {
+	partnerdirectoryCmd := &cobra.Command{
+		Use:     "partnerdirectory",
+		Aliases: []string{"pd"},
+		Short:   "Sync Partner Directory entries between tenant and Git",
+		Long: `Synchronise Partner Directory entries (string parameters, binary parameters,
+alternative partners and authorized users) between SAP Integration Suite
+tenant and a Git repository.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// If artifacts directory is provided, validate that is it a subdirectory of Git repo
+			gitRepoDir, err := config.GetStringWithEnvExpand(cmd, "dir-git-repo")
+			if err != nil {
+				return fmt.Errorf("security alert for --dir-git-repo: %w", err)
+			}
+			if gitRepoDir != "" {
+				artifactsDir, err := config.GetStringWithEnvExpand(cmd, "dir-artifacts")
+				if err != nil {
+					return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+				}
+				gitRepoDirClean := filepath.Clean(gitRepoDir) + string(os.PathSeparator)
+				if artifactsDir != "" && !strings.HasPrefix(artifactsDir, gitRepoDirClean) {
+					return fmt.Errorf("--dir-artifacts [%v] should be a subdirectory of --dir-git-repo [%v]", artifactsDir, gitRepoDirClean)
+				}
+			}
+			// Validate target
+			target := config.GetString(cmd, "target")
+			switch target {
+			case "git", "tenant":
+			default:
+				return fmt.Errorf("invalid value for --target = %v", target)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runSyncPartnerDirectory(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	return partnerdirectoryCmd
+}
+
+func runSyncPartnerDirectory(cmd *cobra.Command) error {
+	log.Info().Msg("Executing sync partnerdirectory command")
+
+	gitRepoDir, err := config.GetStringWithEnvExpand(cmd, "dir-git-repo")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-git-repo: %w", err)
+	}
+	artifactsDir, err := config.GetStringWithEnvExpandWithDefault(cmd, "dir-artifacts", gitRepoDir)
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-artifacts: %w", err)
+	}
+	workDir, err := config.GetStringWithEnvExpand(cmd, "dir-work")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-work: %w", err)
+	}
+	includedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-include"))
+	excludedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-exclude"))
+	commitMsg := config.GetString(cmd, "git-commit-msg")
+	commitUser := config.GetString(cmd, "git-commit-user")
+	commitEmail := config.GetString(cmd, "git-commit-email")
+	skipCommit := config.GetBool(cmd, "git-skip-commit")
+	target := config.GetString(cmd, "target")
+	prune := config.GetBool(cmd, "prune")
+	dryRunPlan := getDryRunPlan(cmd)
+
+	serviceDetails := api.GetServiceDetails(cmd)
+	// Initialise HTTP executer
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	syncer := sync.NewSyncer(target, "PartnerDirectory", exe)
+	partnerdirectoryWorkDir := fmt.Sprintf("%v/partnerdirectory", workDir)
+	err = syncer.Exec(sync.Request{WorkDir: partnerdirectoryWorkDir, ArtifactsDir: artifactsDir, IncludedIds: includedIds, ExcludedIds: excludedIds, Prune: prune, Plan: dryRunPlan, Report: getReport(cmd)})
+	if err != nil {
+		return err
+	}
+	if target == "git" && !skipCommit && dryRunPlan == nil {
+		err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
+		if err != nil {
+			return err
+		}
+	}
+	// Clean up working directory
+	err = os.RemoveAll(partnerdirectoryWorkDir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Keys of a profile that identify the tenant and its credentials
+var profileTenantKeys = []string{"tmn-host", "tmn-userid", "tmn-password", "oauth-host", "oauth-clientid", "oauth-clientsecret", "oauth-path", "client-cert", "client-key", "client-cert-passphrase", "ca-bundle"}
+
+// Commands that connect to the API Portal of APIM, for which apim-host of the profile is used as tmn-host
+var apimCommands = []string{"apiproxy", "apiproduct"}
+
+// profile is a named tenant in the profiles section of the config file
+type profile struct {
+	name       string
+	prefix     string
+	authMethod string
+	hostKey    string
+	values     map[string]string
+	excluded   []string // Keys of the other auth methods, which are not applied from the top level of the config file
+}
+
+// getProfile returns the named profile with the values that are applied to the flags of the command. With a prefix,
+// e.g. target- for --target-tmn-host, only the tenant keys are applied to the flags with the prefix.
+func getProfile(cmd *cobra.Command, name string, prefix string) (*profile, error) {
+	raw, ok := viper.GetStringMap("profiles")[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in profiles section of config file %v", name, viper.ConfigFileUsed())
+	}
+	settings, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("profile %q in config file %v is not a map of keys and values", name, viper.ConfigFileUsed())
+	}
+
+	p := &profile{name: name, prefix: prefix, hostKey: "tmn-host", values: map[string]string{}}
+	for key, val := range settings {
+		p.values[key] = configValue(val)
+	}
+
+	// Only the credentials of the auth method are used, so that a profile can contain both
+	p.authMethod = p.values["auth-method"]
+	if p.authMethod == "" {
+		p.authMethod = "basic"
+		if p.values["oauth-host"] != "" {
+			p.authMethod = "oauth"
+		} else if p.values["client-cert"] != "" {
+			p.authMethod = "certificate"
+		}
+	}
+	switch p.authMethod {
+	case "basic":
+		p.excluded = []string{"oauth-host", "oauth-clientid", "oauth-clientsecret", "client-cert", "client-key", "client-cert-passphrase"}
+	case "oauth":
+		// The OAuth token is requested with the client certificate (X.509) if the profile has one
+		p.excluded = []string{"tmn-userid", "tmn-password"}
+		if p.values["client-cert"] != "" {
+			p.excluded = append(p.excluded, "oauth-clientsecret")
+		}
+	case "certificate":
+		p.excluded = []string{"tmn-userid", "tmn-password", "oauth-host", "oauth-clientid", "oauth-clientsecret"}
+	default:
+		return nil, fmt.Errorf("invalid value for auth-method = %v in profile %q", p.authMethod, name)
+	}
+	deleteKeys(p.values, p.excluded...)
+	delete(p.values, "auth-method")
+
+	if slices.Contains(apimCommands, cmd.Name()) {
+		p.hostKey = "apim-host"
+		if apimHost, ok := p.values["apim-host"]; ok {
+			p.values["tmn-host"] = apimHost
+		} else {
+			delete(p.values, "tmn-host")
+		}
+	}
+	delete(p.values, "apim-host")
+
+	if prefix != "" {
+		values := map[string]string{}
+		for _, key := range profileTenantKeys {
+			if val, ok := p.values[key]; ok {
+				values[prefix+key] = val
+			}
+		}
+		p.values = values
+	}
+	for i, key := range p.excluded {
+		p.excluded[i] = prefix + key
+	}
+	return p, nil
+}
+
+// validate checks that the tenant keys required for the auth method of the profile are set, either in the profile
+// or by the flags and their environment variables
+func (p *profile) validate(cmd *cobra.Command) error {
+	required := [][2]string{{p.hostKey, "tmn-host"}}
+	switch p.authMethod {
+	case "oauth":
+		required = append(required, [2]string{"oauth-host", "oauth-host"}, [2]string{"oauth-clientid", "oauth-clientid"})
+		if config.GetString(cmd, p.prefix+"client-cert") == "" {
+			required = append(required, [2]string{"oauth-clientsecret", "oauth-clientsecret"})
+		}
+	case "certificate":
+		required = append(required, [2]string{"client-cert", "client-cert"})
+	default:
+		required = append(required, [2]string{"tmn-userid", "tmn-userid"}, [2]string{"tmn-password", "tmn-password"})
+	}
+	for _, r := range required {
+		f := cmd.Flags().Lookup(p.prefix + r[1])
+		if f != nil && f.Value.String() == "" {
+			return fmt.Errorf("profile %q is missing key %q required for auth-method %v", p.name, r[0], p.authMethod)
+		}
+	}
+	return nil
+}
+
+func deleteKeys(values map[string]string, keys ...string) {
+	for _, key := range keys {
+		delete(values, key)
+	}
+}
+
+// configValue returns the value from the config file in the format used by the flags
+func configValue(val interface{}) string {
+	if list, ok := val.([]interface{}); ok {
+		var items []string
+		for _, item := range list {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// isEnvSet returns true if the environment variable of the flag is set
+func isEnvSet(flagName string) bool {
+	_, ok := os.LookupEnv("FLASHPIPE_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_")))
+	return ok
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const profilesConfig = `dir-work: /tmp/top-level
+oauth-host: top-level.authentication.hana.ondemand.com
+tmn-userid: top-level-user
+profiles:
+  dev:
+    tmn-host: dev-tmn.hana.ondemand.com
+    apim-host: dev-apim.hana.ondemand.com
+    auth-method: oauth
+    tmn-userid: unused
+    tmn-password: unused
+    oauth-host: dev.authentication.hana.ondemand.com
+    oauth-clientid: dev-client
+    oauth-clientsecret: dev-secret
+    dir-work: /tmp/dev
+  QA:
+    tmn-host: qa-tmn.hana.ondemand.com
+    tmn-userid: qa-user
+  prd:
+    tmn-host: prd-tmn.hana.ondemand.com
+    tmn-userid: unused
+    client-cert: /certs/prd.p12
+  invalid:
+    tmn-host: invalid.hana.ondemand.com
+    auth-method: kerberos
+`
+
+// executeWithProfile runs a command with the args and returns the values of its flags after the config is initialised
+func executeWithProfile(t *testing.T, name string, args ...string) (map[string]string, error) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	configFile := filepath.Join(t.TempDir(), "flashpipe.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte(profilesConfig), 0644))
+
+	values := map[string]string{}
+	testCmd := &cobra.Command{
+		Use: name,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, flagName := range []string{"tmn-host", "tmn-userid", "tmn-password", "oauth-host", "oauth-clientid", "oauth-clientsecret", "dir-work", "client-cert", "target-tmn-host", "target-tmn-userid", "target-oauth-host"} {
+				values[flagName] = config.GetString(cmd, flagName)
+			}
+			return nil
+		},
+	}
+	testCmd.Flags().String("dir-work", "/tmp", "")
+	testCmd.Flags().String("target-profile", "", "")
+	testCmd.Flags().String("target-tmn-host", "", "")
+	testCmd.Flags().String("target-tmn-userid", "", "")
+	testCmd.Flags().String("target-oauth-host", "", "")
+	rootCmd := NewCmdRoot()
+	rootCmd.AddCommand(testCmd)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs(append([]string{name, "--config", configFile}, args...))
+	err := rootCmd.Execute()
+	return values, err
+}
+
+func TestProfile_AppliesValuesOfAuthMethod(t *testing.T) {
+	values, err := executeWithProfile(t, "test", "--profile", "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "dev-tmn.hana.ondemand.com", values["tmn-host"])
+	assert.Equal(t, "dev.authentication.hana.ondemand.com", values["oauth-host"])
+	assert.Equal(t, "dev-client", values["oauth-clientid"])
+	assert.Equal(t, "", values["tmn-userid"], "credentials of other auth method should not be applied")
+	assert.Equal(t, "/tmp/dev", values["dir-work"])
+}
+
+func TestProfile_UsesAPIMHostForAPIMCommands(t *testing.T) {
+	values, err := executeWithProfile(t, "apiproxy", "--profile", "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "dev-apim.hana.ondemand.com", values["tmn-host"])
+}
+
+func TestProfile_FlagAndEnvironmentVariableOverrideProfile(t *testing.T) {
+	t.Setenv("FLASHPIPE_PROFILE", "dev")
+	t.Setenv("FLASHPIPE_OAUTH_CLIENTID", "env-client")
+	values, err := executeWithProfile(t, "test", "--dir-work", "/tmp/flag")
+	assert.NoError(t, err)
+	assert.Equal(t, "dev-tmn.hana.ondemand.com", values["tmn-host"])
+	assert.Equal(t, "env-client", values["oauth-clientid"])
+	assert.Equal(t, "/tmp/flag", values["dir-work"])
+}
+
+func TestProfile_TargetProfileOnlyAppliesTenantKeys(t *testing.T) {
+	t.Setenv("FLASHPIPE_TMN_PASSWORD", "qa-password")
+	values, err := executeWithProfile(t, "test", "--profile", "qa", "--target-profile", "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "qa-tmn.hana.ondemand.com", values["tmn-host"])
+	assert.Equal(t, "qa-user", values["tmn-userid"])
+	assert.Equal(t, "qa-password", values["tmn-password"])
+	assert.Equal(t, "dev-tmn.hana.ondemand.com", values["target-tmn-host"])
+	assert.Equal(t, "dev.authentication.hana.ondemand.com", values["target-oauth-host"])
+	assert.Equal(t, "/tmp/top-level", values["dir-work"], "dir-work of target profile should not be applied")
+}
+
+func TestProfile_CertificateAuthMethod(t *testing.T) {
+	values, err := executeWithProfile(t, "test", "--profile", "prd")
+	assert.NoError(t, err)
+	assert.Equal(t, "prd-tmn.hana.ondemand.com", values["tmn-host"])
+	assert.Equal(t, "/certs/prd.p12", values["client-cert"])
+	assert.Equal(t, "", values["tmn-userid"], "credentials of other auth method should not be applied")
+}
+
+func TestProfile_IgnoresTopLevelCredentialsOfOtherAuthMethod(t *testing.T) {
+	values, err := executeWithProfile(t, "test", "--profile", "qa", "--tmn-password", "qa-password")
+	assert.NoError(t, err)
+	assert.Equal(t, "", values["oauth-host"], "top-level oauth-host should not be applied to basic auth profile")
+
+	values, err = executeWithProfile(t, "test", "--profile", "prd")
+	assert.NoError(t, err)
+	assert.Equal(t, "", values["tmn-userid"], "top-level tmn-userid should not be applied to certificate profile")
+	assert.Equal(t, "", values["oauth-host"], "top-level oauth-host should not be applied to certificate profile")
+
+	// Environment variables are still applied
+	t.Setenv("FLASHPIPE_TMN_USERID", "env-user")
+	t.Setenv("FLASHPIPE_TMN_PASSWORD", "env-password")
+	values, err = executeWithProfile(t, "test", "--profile", "prd")
+	assert.NoError(t, err)
+	assert.Equal(t, "env-user", values["tmn-userid"])
+}
+
+func TestProfile_ValidationErrors(t *testing.T) {
+	_, err := executeWithProfile(t, "test", "--profile", "sandbox")
+	assert.ErrorContains(t, err, `profile "sandbox" not found`)
+
+	_, err = executeWithProfile(t, "test", "--profile", "qa")
+	assert.EqualError(t, err, `profile "qa" is missing key "tmn-password" required for auth-method basic`)
+
+	_, err = executeWithProfile(t, "apiproduct", "--profile", "qa", "--tmn-password", "qa-password")
+	assert.EqualError(t, err, `profile "qa" is missing key "apim-host" required for auth-method basic`)
+
+	_, err = executeWithProfile(t, "test", "--profile", "invalid")
+	assert.EqualError(t, err, `invalid value for auth-method = kerberos in profile "invalid"`)
+
+	_, err = executeWithProfile(t, "test", "--tmn-userid", "user", "--tmn-password", "password")
+	assert.EqualError(t, err, `required flag(s) "tmn-host" not set`)
+}
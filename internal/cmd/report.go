@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+type reportKey struct{}
+
+// initializeReport adds a new report to the context of the command when --report-file is set
+func initializeReport(cmd *cobra.Command) error {
+	if config.GetString(cmd, "report-file") == "" {
+		return nil
+	}
+	format := config.GetString(cmd, "report-format")
+	switch format {
+	case report.FormatJSON, report.FormatJUnit:
+	default:
+		return fmt.Errorf("invalid value for --report-format = %v", format)
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	commandName := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	cmd.SetContext(context.WithValue(ctx, reportKey{}, report.New(commandName)))
+	return nil
+}
+
+// getReport returns the report of the command, or nil when --report-file is not set
+func getReport(cmd *cobra.Command) *report.Report {
+	ctx := cmd.Context()
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(reportKey{}).(*report.Report)
+	return r
+}
+
+// writeReport writes the report of the command to --report-file. The error of the command is returned, or the error
+// writing the report if the command was successful
+func writeReport(cmd *cobra.Command, cmdErr error) error {
+	r := getReport(cmd)
+	if r == nil {
+		return cmdErr
+	}
+	reportFile := config.GetString(cmd, "report-file")
+	err := r.Write(reportFile, config.GetString(cmd, "report-format"), cmdErr)
+	if err != nil {
+		if cmdErr != nil {
+			log.Error().Msgf("Failed to write report file %v: %v", reportFile, err)
+			return cmdErr
+		}
+		return err
+	}
+	log.Info().Msgf("Report written to %v", reportFile)
+	return cmdErr
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
 	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/engswee/flashpipe/internal/sync"
 	"github.com/go-errors/errors"
@@ -13,6 +16,7 @@ import (
 	"github.com/spf13/cobra"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -45,7 +49,7 @@ func NewRestoreCommand() *cobra.Command {
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runRestore(cmd); err != nil {
+			if err = writeReport(cmd, runRestore(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -53,6 +57,8 @@ func NewRestoreCommand() *cobra.Command {
 		},
 	}
 
+	restoreCmd.Flags().Bool("dry-run", false, dryRunUsage)
+
 	return restoreCmd
 }
 
@@ -73,17 +79,22 @@ func runRestore(cmd *cobra.Command) error {
 	}
 	includedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-include"))
 	excludedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-exclude"))
+	prune := config.GetBool(cmd, "prune")
+	dryRunPlan := getDryRunPlan(cmd)
 
 	serviceDetails := api.GetServiceDetails(cmd)
-	err = restoreSnapshot(serviceDetails, artifactsBaseDir, workDir, includedIds, excludedIds)
+	err = restoreSnapshot(serviceDetails, artifactsBaseDir, workDir, includedIds, excludedIds, prune, dryRunPlan, getReport(cmd))
 	if err != nil {
 		return err
 	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
 
 	return nil
 }
 
-func restoreSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string, workDir string, includedIds []string, excludedIds []string) error {
+func restoreSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string, workDir string, includedIds []string, excludedIds []string, prune bool, dryRunPlan *plan.Plan, rep *report.Report) error {
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msg("📢 Begin restoring snapshot to the tenant")
 
@@ -98,8 +109,12 @@ func restoreSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string
 	exe := api.InitHTTPExecuter(serviceDetails)
 	packageSynchroniser := sync.NewSyncer("tenant", "CPIPackage", exe)
 	artifactsSynchroniser := sync.New(exe)
+	artifactsSynchroniser.SetPrune(prune)
+	artifactsSynchroniser.SetDryRun(dryRunPlan)
+	artifactsSynchroniser.SetReport(rep)
 
 	// Go through each directory and check if there is an integration package details in it, if yes, then proceed to restore integration package and artifacts
+	var gitPackageIds []string
 	for _, entry := range entries {
 		packageId := entry.Name()
 		packageDir := fmt.Sprintf("%v/%v", baseSourceDir, packageId)
@@ -108,13 +123,14 @@ func restoreSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string
 			log.Info().Msg("---------------------------------------------------------------------------------")
 			log.Info().Msgf("Processing directory %v", packageDir)
 			if file.Exists(packageFile) {
+				gitPackageIds = append(gitPackageIds, packageId)
 				// Filter in/out packages
 				if str.FilterIDs(packageId, includedIds, excludedIds) {
 					continue
 				}
 
 				// 1 - Sync CPI Integration Package
-				err = packageSynchroniser.Exec(sync.Request{ArtifactsDir: packageDir})
+				err = packageSynchroniser.Exec(sync.Request{ArtifactsDir: packageDir, Plan: dryRunPlan, Report: rep})
 				if err != nil {
 					return err
 				}
@@ -130,7 +146,55 @@ func restoreSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string
 		}
 	}
 
+	if prune {
+		if len(gitPackageIds) == 0 {
+			log.Warn().Msgf("Skipping pruning of packages as no integration package found in %v", baseSourceDir)
+		} else {
+			err = pruneTenantPackages(exe, artifactsSynchroniser, gitPackageIds, includedIds, excludedIds)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msg("🏆 Completed restoring snapshot to the tenant")
 	return nil
 }
+
+// pruneTenantPackages deletes editable packages from the tenant that no longer exist in Git
+func pruneTenantPackages(exe *httpclnt.HTTPExecuter, synchroniser *sync.Synchroniser, gitPackageIds []string, includedIds []string, excludedIds []string) error {
+	ip := api.NewIntegrationPackage(exe)
+	tenantPackageIds, err := ip.GetPackagesList()
+	if err != nil {
+		return err
+	}
+	var orphans []string
+	for _, packageId := range tenantPackageIds {
+		if slices.Contains(gitPackageIds, packageId) || str.FilterIDs(packageId, includedIds, excludedIds) {
+			continue
+		}
+		_, readOnly, _, err := ip.Get(packageId)
+		if err != nil {
+			return err
+		}
+		// Configure-only packages are not part of a snapshot
+		if !readOnly {
+			orphans = append(orphans, packageId)
+		}
+	}
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	if len(orphans) == 0 {
+		log.Info().Msg("📢 No package found for pruning from tenant")
+		return nil
+	}
+	log.Info().Msgf("📢 Pruning %d package(s) from tenant: %v", len(orphans), strings.Join(orphans, ", "))
+	for _, packageId := range orphans {
+		log.Info().Msgf("Package %v does not exist in Git, and will be deleted from tenant", packageId)
+		err = synchroniser.DeletePackage(packageId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/engswee/flashpipe/internal/config"
@@ -32,11 +33,20 @@ automating time-consuming manual tasks like:
 		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// You can bind cobra and viper in a few locations, but PersistencePreRunE on the root command works well
-			return initializeConfig(cmd)
+			profiles, err := initializeConfig(cmd)
+			if err != nil {
+				return err
+			}
+			err = validateTenantConfig(cmd, profiles)
+			if err != nil {
+				return err
+			}
+			return initializeReport(cmd)
 		},
 	}
 
 	rootCmd.PersistentFlags().String("config", "", "config file (default is $HOME/flashpipe.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "Name of tenant profile in the profiles section of the config file")
 
 	// Define cobra flags, the default value has the lowest (least significant) precedence
 	rootCmd.PersistentFlags().String("tmn-host", "", "Host for tenant management node of Cloud Integration or API Portal node of APIM excluding https://")
@@ -46,12 +56,24 @@ automating time-consuming manual tasks like:
 	rootCmd.PersistentFlags().String("oauth-clientid", "", "Client ID for using OAuth")
 	rootCmd.PersistentFlags().String("oauth-clientsecret", "", "Client Secret for using OAuth")
 	rootCmd.PersistentFlags().String("oauth-path", "/oauth/token", "Path for OAuth token server")
+	rootCmd.PersistentFlags().String("client-cert", "", "PEM or PKCS#12 (.p12, .pfx) file with client certificate for mTLS to tenant or X.509 OAuth")
+	rootCmd.PersistentFlags().String("client-key", "", "PEM file with private key of client certificate, if not contained in --client-cert")
+	rootCmd.PersistentFlags().String("client-cert-passphrase", "", "Passphrase of PKCS#12 file or encrypted private key of client certificate")
+	rootCmd.PersistentFlags().String("ca-bundle", "", "PEM file with CA certificates trusted in addition to the system certificates")
+
+	rootCmd.PersistentFlags().Int("retry-max-attempts", 3, "Max number of attempts for HTTP calls that fail with transient errors (e.g. 429, 502, 503)")
+	rootCmd.PersistentFlags().Int("retry-initial-delay", 1, "Delay (in seconds) before the first retry, doubled for each subsequent retry")
+	rootCmd.PersistentFlags().Int("retry-max-delay", 30, "Max delay (in seconds) between retries")
+	// To set to false, use --retry-jitter=false
+	rootCmd.PersistentFlags().Bool("retry-jitter", true, "Randomise delay between retries")
+
+	rootCmd.PersistentFlags().String("report-file", "", "Write a report of the processed artifacts to this file")
+	rootCmd.PersistentFlags().String("report-format", "json", "Format of the report file. Allowed values: json, junit")
 
 	rootCmd.PersistentFlags().Bool("debug", false, "Show debug logs")
 
-	_ = rootCmd.MarkPersistentFlagRequired("tmn-host")
 	rootCmd.MarkFlagsRequiredTogether("tmn-userid", "tmn-password")
-	rootCmd.MarkFlagsRequiredTogether("oauth-host", "oauth-clientid", "oauth-clientsecret")
+	rootCmd.MarkFlagsRequiredTogether("oauth-host", "oauth-clientid")
 
 	return rootCmd
 }
@@ -62,9 +84,16 @@ func Execute() {
 
 	rootCmd := NewCmdRoot()
 	rootCmd.AddCommand(NewDeployCommand())
+	rootCmd.AddCommand(NewUndeployCommand())
+	rootCmd.AddCommand(NewStatusCommand())
+	rootCmd.AddCommand(NewLogsCommand())
+	rootCmd.AddCommand(NewSecurityMaterialCommand())
+	rootCmd.AddCommand(NewKeystoreCommand())
+	rootCmd.AddCommand(NewTransportCommand())
 	syncCmd := NewSyncCommand()
 	syncCmd.AddCommand(NewAPIProxyCommand())
 	syncCmd.AddCommand(NewAPIProductCommand())
+	syncCmd.AddCommand(NewPartnerDirectoryCommand())
 	rootCmd.AddCommand(syncCmd)
 	updateCmd := NewUpdateCommand()
 	updateCmd.AddCommand(NewArtifactCommand())
@@ -83,7 +112,9 @@ func Execute() {
 	}
 }
 
-func initializeConfig(cmd *cobra.Command) error {
+// initializeConfig binds the flags of the command to the config file, environment variables and tenant profiles. The
+// selected profiles are returned for validateTenantConfig.
+func initializeConfig(cmd *cobra.Command) ([]*profile, error) {
 	cfgFile := config.GetString(cmd, "config")
 	if cfgFile != "" {
 		// Use config file from the flag.
@@ -102,7 +133,7 @@ func initializeConfig(cmd *cobra.Command) error {
 	if err := viper.ReadInConfig(); err != nil {
 		// It's okay if there isn't a config file
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
+			return nil, err
 		}
 	}
 
@@ -115,27 +146,88 @@ func initializeConfig(cmd *cobra.Command) error {
 	// Bind to environment variables
 	viper.AutomaticEnv()
 
+	// Get the tenant profiles, whose values are applied to flags that are not set on the command line
+	var profiles []*profile
+	profileValues := map[string]string{}
+	var excludedKeys []string
+	for _, flag := range [][2]string{{"profile", ""}, {"target-profile", "target-"}} {
+		name := profileName(cmd, flag[0])
+		if name == "" {
+			continue
+		}
+		p, err := getProfile(cmd, name, flag[1])
+		if err != nil {
+			return nil, err
+		}
+		for key, val := range p.values {
+			profileValues[key] = val
+		}
+		excludedKeys = append(excludedKeys, p.excluded...)
+		profiles = append(profiles, p)
+	}
+
 	// Bind the current command's flags to viper
-	bindFlags(cmd)
+	bindFlags(cmd, profileValues, excludedKeys)
 
 	// Set debug flag from command line to viper
 	if !viper.IsSet("debug") {
 		viper.Set("debug", config.GetBool(cmd, "debug"))
 	}
 
-	if config.GetString(cmd, "oauth-host") == "" && config.GetString(cmd, "tmn-userid") == "" {
-		return fmt.Errorf("required flag \"tmn-userid\" (Basic Auth) or \"oauth-host\" (OAuth) not set")
-	}
-
 	logger.InitConsoleLogger(viper.GetBool("debug"))
 
+	return profiles, nil
+}
+
+// validateTenantConfig checks that the tenant host and the credentials of the tenant are set
+func validateTenantConfig(cmd *cobra.Command, profiles []*profile) error {
+	for _, p := range profiles {
+		if err := p.validate(cmd); err != nil {
+			return err
+		}
+	}
+	if config.GetString(cmd, "tmn-host") == "" {
+		return fmt.Errorf("required flag(s) \"tmn-host\" not set")
+	}
+	return validateAuthFlags(cmd, "")
+}
+
+// validateAuthFlags checks that the credentials of one of the auth methods are set in the flags with the prefix
+func validateAuthFlags(cmd *cobra.Command, prefix string) error {
+	if config.GetString(cmd, prefix+"oauth-host") == "" && config.GetString(cmd, prefix+"tmn-userid") == "" && config.GetString(cmd, prefix+"client-cert") == "" {
+		return fmt.Errorf("required flag \"%vtmn-userid\" (Basic Auth), \"%voauth-host\" (OAuth) or \"%vclient-cert\" (client certificate) not set", prefix, prefix, prefix)
+	}
+	if config.GetString(cmd, prefix+"oauth-host") != "" && config.GetString(cmd, prefix+"oauth-clientsecret") == "" && config.GetString(cmd, prefix+"client-cert") == "" {
+		return fmt.Errorf("required flag \"%voauth-clientsecret\" (OAuth) or \"%vclient-cert\" (X.509 OAuth) not set", prefix, prefix)
+	}
 	return nil
 }
 
-// Bind each cobra flag to its associated viper configuration (config file and environment variable)
-func bindFlags(cmd *cobra.Command) {
+// profileName returns the name of the profile selected by the flag, its environment variable or the config file
+func profileName(cmd *cobra.Command, flagName string) string {
+	f := cmd.Flags().Lookup(flagName)
+	if f == nil {
+		return ""
+	}
+	if f.Changed {
+		return f.Value.String()
+	}
+	return viper.GetString(flagName)
+}
+
+// Bind each cobra flag to its associated viper configuration (config file and environment variable). Values of the
+// profile take precedence over the config file, but not over environment variables. The excluded keys are only bound
+// to environment variables, so that the config file does not add credentials of another auth method to a profile.
+func bindFlags(cmd *cobra.Command, profileValues map[string]string, excludedKeys []string) {
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		configName := f.Name
+		if slices.Contains(excludedKeys, configName) && !isEnvSet(configName) {
+			return
+		}
+		if val, ok := profileValues[configName]; ok && !f.Changed && !isEnvSet(configName) {
+			// Set in viper so that the value is also known to the check for sensitive content
+			viper.Set(configName, val)
+		}
 		// Apply the viper config value to the flag when the flag is not set and viper has a value
 		if !f.Changed && viper.IsSet(configName) {
 			val := viper.Get(configName)
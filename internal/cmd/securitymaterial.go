@@ -0,0 +1,424 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/secret"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const secretsKeyUsage = "Base64 encoded 256-bit key to decrypt encrypted values, e.g. generated with openssl rand -base64 32"
+
+func NewSecurityMaterialCommand() *cobra.Command {
+
+	securityMaterialCmd := &cobra.Command{
+		Use:   "security-material",
+		Short: "Manage security material",
+		Long: `Manage user credentials, OAuth2 client credentials and secure parameters
+on SAP Integration Suite tenant.`,
+	}
+	securityMaterialCmd.AddCommand(newSecurityMaterialListCommand())
+	securityMaterialCmd.AddCommand(newSecurityMaterialApplyCommand())
+	securityMaterialCmd.AddCommand(newSecurityMaterialDeleteCommand())
+	securityMaterialCmd.AddCommand(newSecurityMaterialEncryptCommand())
+	return securityMaterialCmd
+}
+
+func newSecurityMaterialListCommand() *cobra.Command {
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List security material",
+		Long: `List the security material on SAP Integration Suite tenant.
+Secret values are never returned by the tenant.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			output := config.GetString(cmd, "output")
+			switch output {
+			case outputTable, outputJSON:
+			default:
+				return fmt.Errorf("invalid value for --output = %v", output)
+			}
+			return validateSecurityMaterialTypes(config.GetStringSlice(cmd, "types"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runSecurityMaterialList(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	listCmd.Flags().StringSlice("types", api.SecurityMaterialTypes, "Comma separated list of security material types. Allowed values: UserCredentials, OAuth2ClientCredentials, SecureParameters")
+	listCmd.Flags().String("output", outputTable, "Output format. Allowed values: table, json")
+	return listCmd
+}
+
+func newSecurityMaterialApplyCommand() *cobra.Command {
+
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create or update security material from file",
+		Long: `Create or update the security material defined in a file on
+SAP Integration Suite tenant. Secret values are taken from environment
+variables or decrypted from encrypted values in the file.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runSecurityMaterialApply(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	applyCmd.Flags().String("file", "", "Path to YAML file with security material")
+	applyCmd.Flags().String("secrets-key", "", secretsKeyUsage)
+	applyCmd.Flags().Bool("dry-run", false, dryRunUsage)
+
+	_ = applyCmd.MarkFlagRequired("file")
+	return applyCmd
+}
+
+func newSecurityMaterialDeleteCommand() *cobra.Command {
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete security material",
+		Long:  `Delete security material from SAP Integration Suite tenant.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateSecurityMaterialTypes([]string{config.GetString(cmd, "type")})
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runSecurityMaterialDelete(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	deleteCmd.Flags().String("type", "", "Security material type. Allowed values: UserCredentials, OAuth2ClientCredentials, SecureParameters")
+	deleteCmd.Flags().StringSlice("names", nil, "Comma separated list of names of security material")
+	deleteCmd.Flags().Bool("dry-run", false, dryRunUsage)
+
+	_ = deleteCmd.MarkFlagRequired("type")
+	_ = deleteCmd.MarkFlagRequired("names")
+	return deleteCmd
+}
+
+func newSecurityMaterialEncryptCommand() *cobra.Command {
+
+	encryptCmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt a secret value",
+		Long: `Encrypt a secret value read from stdin, so that it can be
+stored in the security material file.`,
+		// No connection to the tenant is required, so the tenant host and credentials are not validated
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			_, err := initializeConfig(cmd)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runSecurityMaterialEncrypt(cmd)
+			if err != nil {
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	encryptCmd.Flags().String("secrets-key", "", secretsKeyUsage)
+	return encryptCmd
+}
+
+func validateSecurityMaterialTypes(types []string) error {
+	for _, typ := range types {
+		if !slices.Contains(api.SecurityMaterialTypes, typ) {
+			return fmt.Errorf("invalid value for security material type = %v", typ)
+		}
+	}
+	return nil
+}
+
+func runSecurityMaterialList(cmd *cobra.Command) error {
+	log.Info().Msg("Executing security-material list command")
+
+	types := str.TrimSlice(config.GetStringSlice(cmd, "types"))
+	output := config.GetString(cmd, "output")
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	all := map[string][]*api.SecurityMaterialEntry{}
+	for _, typ := range types {
+		entries, err := api.NewSecurityMaterial(typ, exe).GetAll()
+		if err != nil {
+			return err
+		}
+		all[typ] = entries
+	}
+	return writeSecurityMaterial(cmd.OutOrStdout(), types, all, output)
+}
+
+// writeSecurityMaterial writes the entries of each type to w in the output format
+func writeSecurityMaterial(w io.Writer, types []string, all map[string][]*api.SecurityMaterialEntry, output string) error {
+	switch output {
+	case outputJSON:
+		content := map[string][]map[string]string{}
+		for _, typ := range types {
+			content[typ] = []map[string]string{}
+			for _, entry := range all[typ] {
+				properties := map[string]string{"Name": entry.Name}
+				for property, value := range entry.Properties {
+					properties[property] = value
+				}
+				content[typ] = append(content[typ], properties)
+			}
+		}
+		jsonContent, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		_, err = fmt.Fprintln(w, string(jsonContent))
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TYPE\tNAME\tDESCRIPTION\tDEPLOYED BY\tSTATUS")
+		for _, typ := range types {
+			for _, entry := range all[typ] {
+				fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", typ, entry.Name, dash(entry.Properties["Description"]), dash(entry.Properties["DeployedBy"]), dash(entry.Properties["Status"]))
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return errors.Wrap(err, 0)
+		}
+	default:
+		return fmt.Errorf("invalid output format %v", output)
+	}
+	return nil
+}
+
+func runSecurityMaterialApply(cmd *cobra.Command) error {
+	log.Info().Msg("Executing security-material apply command")
+
+	filePath := config.GetString(cmd, "file")
+	dryRunPlan := getDryRunPlan(cmd)
+	key, err := getSecretsKey(cmd)
+	if err != nil {
+		return err
+	}
+
+	material, err := readSecurityMaterialFile(filePath, key)
+	if err != nil {
+		return err
+	}
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	err = applySecurityMaterial(material, exe, dryRunPlan, getReport(cmd))
+	if err != nil {
+		return err
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
+	return nil
+}
+
+// securityMaterialFile is the content of the security material file, with the entries of each type
+type securityMaterialFile map[string][]map[string]string
+
+// readSecurityMaterialFile reads the entries of the security material file, and resolves values that reference
+// environment variables or are encrypted
+func readSecurityMaterialFile(filePath string, key []byte) (map[string][]*api.SecurityMaterialEntry, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	var data securityMaterialFile
+	err = yaml.Unmarshal(content, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Security material file %v is not valid YAML: %w", filePath, err)
+	}
+
+	material := map[string][]*api.SecurityMaterialEntry{}
+	for typ, items := range data {
+		if !slices.Contains(api.SecurityMaterialTypes, typ) {
+			return nil, fmt.Errorf("Security material file %v has invalid type %v", filePath, typ)
+		}
+		for i, item := range items {
+			entry := &api.SecurityMaterialEntry{Name: item["Name"], Properties: map[string]string{}}
+			if entry.Name == "" {
+				return nil, fmt.Errorf("Entry %d of %v in security material file has no Name", i+1, typ)
+			}
+			for property, value := range item {
+				if property == "Name" {
+					continue
+				}
+				if api.IsSecretProperty(typ, property) && !secret.IsEncrypted(value) && !secret.IsEnvReference(value) {
+					log.Warn().Msgf("%v of %v %v is stored in plain text, use an environment variable or encrypted value instead", property, typ, entry.Name)
+				}
+				resolved, err := secret.Resolve(value, key)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to resolve %v of %v %v: %w", property, typ, entry.Name, err)
+				}
+				entry.Properties[property] = resolved
+			}
+			material[typ] = append(material[typ], entry)
+		}
+	}
+	return material, nil
+}
+
+// applySecurityMaterial creates the entries that do not exist in the tenant, and updates the existing entries. As the
+// secret values cannot be read from the tenant for comparison, existing entries are always updated.
+func applySecurityMaterial(material map[string][]*api.SecurityMaterialEntry, exe *httpclnt.HTTPExecuter, dryRunPlan *plan.Plan, rep *report.Report) error {
+	for _, typ := range api.SecurityMaterialTypes {
+		sm := api.NewSecurityMaterial(typ, exe)
+		for _, entry := range material[typ] {
+			log.Info().Msg("---------------------------------------------------------------------------------")
+			log.Info().Msgf("Processing %v %v", typ, entry.Name)
+			start := time.Now()
+			reportEntry := report.Entry{ArtifactType: typ, Id: entry.Name, Target: "tenant"}
+			exists, err := sm.Exists(entry.Name)
+			if err != nil {
+				rep.AddError(start, reportEntry, err)
+				return err
+			}
+			action, planAction := report.Created, plan.Create
+			if exists {
+				action, planAction = report.Updated, plan.Update
+			}
+			switch {
+			case dryRunPlan != nil:
+				dryRunPlan.Add(planAction, typ, entry.Name, "tenant", "")
+			case exists:
+				err = sm.Update(entry)
+			default:
+				err = sm.Create(entry)
+			}
+			if err != nil {
+				rep.AddError(start, reportEntry, err)
+				return err
+			}
+			reportEntry.Action = action
+			rep.Add(start, reportEntry)
+			if dryRunPlan == nil {
+				log.Info().Msgf("🏆 %v %v %v successfully", typ, entry.Name, action)
+			}
+		}
+	}
+	return nil
+}
+
+func runSecurityMaterialDelete(cmd *cobra.Command) error {
+	log.Info().Msg("Executing security-material delete command")
+
+	typ := config.GetString(cmd, "type")
+	names := str.TrimSlice(config.GetStringSlice(cmd, "names"))
+	dryRunPlan := getDryRunPlan(cmd)
+	rep := getReport(cmd)
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	sm := api.NewSecurityMaterial(typ, exe)
+	for _, name := range names {
+		start := time.Now()
+		entry := report.Entry{ArtifactType: typ, Id: name, Target: "tenant"}
+		exists, err := sm.Exists(name)
+		if err != nil {
+			rep.AddError(start, entry, err)
+			return err
+		}
+		switch {
+		case !exists:
+			log.Warn().Msgf("%v %v does not exist. Skipping deletion", typ, name)
+			entry.Action = report.Skipped
+			entry.Message = "not found"
+		case dryRunPlan != nil:
+			dryRunPlan.Add(plan.Delete, typ, name, "tenant", "")
+			entry.Action = report.Deleted
+		default:
+			err = sm.Delete(name)
+			if err != nil {
+				rep.AddError(start, entry, err)
+				return err
+			}
+			log.Info().Msgf("🏆 %v %v deleted successfully", typ, name)
+			entry.Action = report.Deleted
+		}
+		rep.Add(start, entry)
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
+	return nil
+}
+
+func runSecurityMaterialEncrypt(cmd *cobra.Command) error {
+	key, err := getSecretsKey(cmd)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return fmt.Errorf("required flag \"secrets-key\" not set")
+	}
+	value, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, 0)
+	}
+	value = strings.TrimRight(value, "\r\n")
+	if value == "" {
+		return fmt.Errorf("No value provided in stdin")
+	}
+	encrypted, err := secret.Encrypt(value, key)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), encrypted)
+	return err
+}
+
+// getSecretsKey returns the key of --secrets-key, or nil if it is not set
+func getSecretsKey(cmd *cobra.Command) ([]byte, error) {
+	encoded := config.GetString(cmd, "secrets-key")
+	if encoded == "" {
+		return nil, nil
+	}
+	key, err := secret.ParseKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for --secrets-key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/secret"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSecurityMaterialFile(t *testing.T) {
+	t.Setenv("FLASHPIPE_TEST_CLIENT_SECRET", "clientsecret")
+	key, _ := secret.ParseKey("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	encrypted, _ := secret.Encrypt("password", key)
+	filePath := filepath.Join(t.TempDir(), "security-material.yaml")
+	err := os.WriteFile(filePath, []byte(`UserCredentials:
+  - Name: SFTP_User
+    User: sftpuser
+    Password: `+encrypted+`
+OAuth2ClientCredentials:
+  - Name: S4_OAuth
+    ClientId: client
+    ClientSecret: ${FLASHPIPE_TEST_CLIENT_SECRET}
+`), 0644)
+	assert.NoError(t, err)
+
+	material, err := readSecurityMaterialFile(filePath, key)
+
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(material[api.UserCredentials])) {
+		assert.Equal(t, "SFTP_User", material[api.UserCredentials][0].Name)
+		assert.Equal(t, map[string]string{"User": "sftpuser", "Password": "password"}, material[api.UserCredentials][0].Properties)
+	}
+	if assert.Equal(t, 1, len(material[api.OAuth2ClientCredentials])) {
+		assert.Equal(t, "clientsecret", material[api.OAuth2ClientCredentials][0].Properties["ClientSecret"])
+	}
+}
+
+func TestReadSecurityMaterialFile_InvalidType(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "security-material.yaml")
+	_ = os.WriteFile(filePath, []byte("Certificates:\n  - Name: Cert1\n"), 0644)
+
+	_, err := readSecurityMaterialFile(filePath, nil)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "has invalid type Certificates")
+	}
+}
+
+func TestApplySecurityMaterial(t *testing.T) {
+	var requests []string
+	var created map[string]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-csrf-token", "token123")
+	})
+	mux.HandleFunc("/api/v1/UserCredentials('Existing')", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" Existing")
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/api/v1/SecureParameters('New')", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" New")
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/v1/SecureParameters", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" SecureParameters")
+		_ = json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	material := map[string][]*api.SecurityMaterialEntry{
+		api.UserCredentials:  {{Name: "Existing", Properties: map[string]string{"User": "user", "Password": "password"}}},
+		api.SecureParameters: {{Name: "New", Properties: map[string]string{"SecureParam": "value"}}},
+	}
+	rep := report.New("security-material apply")
+	err := applySecurityMaterial(material, exe, nil, rep)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET Existing", "PUT Existing", "GET New", "POST SecureParameters"}, requests)
+	assert.Equal(t, map[string]string{"Name": "New", "SecureParam": "value"}, created)
+	entries := rep.Entries()
+	if assert.Equal(t, 2, len(entries)) {
+		assert.Equal(t, report.Updated, entries[0].Action)
+		assert.Equal(t, report.Created, entries[1].Action)
+	}
+}
+
+func TestSecurityMaterialEncrypt_KeyFromConfigFile(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	configFile := filepath.Join(t.TempDir(), "flashpipe.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte("secrets-key: MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"), 0644))
+
+	// No tenant host or credentials are required to encrypt a value
+	rootCmd := NewCmdRoot()
+	rootCmd.AddCommand(NewSecurityMaterialCommand())
+	var output bytes.Buffer
+	rootCmd.SetIn(strings.NewReader("password\n"))
+	rootCmd.SetOut(&output)
+	rootCmd.SetArgs([]string{"security-material", "encrypt", "--config", configFile})
+	assert.NoError(t, rootCmd.Execute())
+
+	key, _ := secret.ParseKey("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	decrypted, err := secret.Decrypt(strings.TrimSpace(output.String()), key)
+	assert.NoError(t, err)
+	assert.Equal(t, "password", decrypted)
+}
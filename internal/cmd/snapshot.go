@@ -4,15 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/engswee/flashpipe/internal/analytics"
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/repo"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -52,7 +56,7 @@ tenant to a Git repository.`,
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runSnapshot(cmd); err != nil {
+			if err = writeReport(cmd, runSnapshot(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -73,6 +77,7 @@ tenant to a Git repository.`,
 	snapshotCmd.Flags().String("git-commit-email", "41898282+github-actions[bot]@users.noreply.github.com", "Email used in commit")
 	snapshotCmd.Flags().Bool("git-skip-commit", false, "Skip committing changes to Git repository")
 	snapshotCmd.Flags().Bool("sync-package-details", true, "Sync details of Integration Packages")
+	snapshotCmd.PersistentFlags().Bool("prune", false, "Delete packages and artifacts in target that no longer exist in source")
 
 	_ = snapshotCmd.MarkFlagRequired("dir-git-repo")
 	snapshotCmd.MarkFlagsMutuallyExclusive("ids-include", "ids-exclude")
@@ -103,9 +108,10 @@ func runSnapshot(cmd *cobra.Command) error {
 	commitEmail := config.GetString(cmd, "git-commit-email")
 	skipCommit := config.GetBool(cmd, "git-skip-commit")
 	syncPackageLevelDetails := config.GetBool(cmd, "sync-package-details")
+	prune := config.GetBool(cmd, "prune")
 
 	serviceDetails := api.GetServiceDetails(cmd)
-	err = getTenantSnapshot(serviceDetails, artifactsBaseDir, workDir, draftHandling, syncPackageLevelDetails, includedIds, excludedIds)
+	err = getTenantSnapshot(serviceDetails, artifactsBaseDir, workDir, draftHandling, syncPackageLevelDetails, includedIds, excludedIds, prune, getReport(cmd))
 	if err != nil {
 		return err
 	}
@@ -119,7 +125,7 @@ func runSnapshot(cmd *cobra.Command) error {
 	return nil
 }
 
-func getTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string, workDir string, draftHandling string, syncPackageLevelDetails bool, includedIds []string, excludedIds []string) error {
+func getTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir string, workDir string, draftHandling string, syncPackageLevelDetails bool, includedIds []string, excludedIds []string, prune bool, rep *report.Report) error {
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msg("📢 Begin taking a snapshot of the tenant")
 
@@ -138,6 +144,8 @@ func getTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir stri
 
 	log.Info().Msgf("Processing %d packages", len(ids))
 	synchroniser := sync.New(exe)
+	synchroniser.SetPrune(prune)
+	synchroniser.SetReport(rep)
 	for i, id := range ids {
 		log.Info().Msg("---------------------------------------------------------------------------------")
 		log.Info().Msgf("Processing package %d/%d - ID: %v", i+1, len(ids), id)
@@ -166,7 +174,44 @@ func getTenantSnapshot(serviceDetails *api.ServiceDetails, artifactsBaseDir stri
 		}
 	}
 
+	if prune {
+		err = pruneSnapshotPackages(artifactsBaseDir, ids, includedIds, excludedIds)
+		if err != nil {
+			return err
+		}
+	}
+
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msg("🏆 Completed taking a snapshot of the tenant")
 	return nil
 }
+
+// pruneSnapshotPackages removes package directories from Git for packages that no longer exist in the tenant
+func pruneSnapshotPackages(artifactsBaseDir string, tenantPackageIds []string, includedIds []string, excludedIds []string) error {
+	entries, err := os.ReadDir(artifactsBaseDir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	var orphans []string
+	for _, entry := range entries {
+		packageId := entry.Name()
+		if entry.IsDir() && file.Exists(fmt.Sprintf("%v/%v/%v.json", artifactsBaseDir, packageId, packageId)) &&
+			!slices.Contains(tenantPackageIds, packageId) && !str.FilterIDs(packageId, includedIds, excludedIds) {
+			orphans = append(orphans, packageId)
+		}
+	}
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	if len(orphans) == 0 {
+		log.Info().Msg("📢 No package found for pruning from Git")
+		return nil
+	}
+	log.Info().Msgf("📢 Pruning %d package(s) from Git: %v", len(orphans), strings.Join(orphans, ", "))
+	for _, packageId := range orphans {
+		log.Info().Msgf("🏆 Package %v does not exist in tenant, and will be removed from Git", packageId)
+		err = os.RemoveAll(fmt.Sprintf("%v/%v", artifactsBaseDir, packageId))
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
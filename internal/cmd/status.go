@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// State of a runtime artifact compared to its designtime artifact
+const (
+	stateUpToDate     = "UP_TO_DATE"
+	stateOutdated     = "OUTDATED"
+	stateDraft        = "DRAFT"
+	stateNotDeployed  = "NOT_DEPLOYED"
+	stateNoDesigntime = "NO_DESIGNTIME"
+)
+
+// Supported output formats of the status command
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+type artifactStatus struct {
+	Id                string `json:"id"`
+	Name              string `json:"name"`
+	Type              string `json:"type"`
+	RuntimeVersion    string `json:"runtimeVersion"`
+	DesigntimeVersion string `json:"designtimeVersion"`
+	Status            string `json:"status"`
+	State             string `json:"state"`
+	DeployedBy        string `json:"deployedBy"`
+	DeployedOn        string `json:"deployedOn"`
+}
+
+func NewStatusCommand() *cobra.Command {
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "List status of runtime artifacts",
+		Long: `List the artifacts deployed to the runtime of SAP Integration Suite
+tenant, and compare their versions with the designtime artifacts.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate the output format
+			output := config.GetString(cmd, "output")
+			switch output {
+			case outputTable, outputJSON, outputCSV:
+			default:
+				return fmt.Errorf("invalid value for --output = %v", output)
+			}
+			// Validate the ID pattern
+			if _, err := path.Match(config.GetString(cmd, "id-pattern"), ""); err != nil {
+				return fmt.Errorf("invalid value for --id-pattern = %v", config.GetString(cmd, "id-pattern"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runStatus(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	statusCmd.Flags().String("package-id", "", "ID of integration package whose artifacts are listed, including those not deployed")
+	statusCmd.Flags().String("id-pattern", "", "Only list artifacts whose ID matches this pattern, e.g. Order_*")
+	statusCmd.Flags().String("output", outputTable, "Output format. Allowed values: table, json, csv")
+	statusCmd.Flags().String("output-file", "", "Write the output to this file instead of stdout")
+	statusCmd.Flags().Bool("wait", false, "Wait until no listed artifact is in STARTING status")
+	statusCmd.Flags().Int("delay-length", 30, "Delay (in seconds) between each check of artifact runtime status when waiting")
+	statusCmd.Flags().Int("max-check-limit", 10, "Max number of times to check for artifact runtime status when waiting")
+
+	return statusCmd
+}
+
+func runStatus(cmd *cobra.Command) error {
+	log.Info().Msg("Executing status command")
+
+	packageId := config.GetString(cmd, "package-id")
+	idPattern := config.GetString(cmd, "id-pattern")
+	output := config.GetString(cmd, "output")
+	outputFile := config.GetString(cmd, "output-file")
+	wait := config.GetBool(cmd, "wait")
+	delayLength := config.GetInt(cmd, "delay-length")
+	maxCheckLimit := config.GetInt(cmd, "max-check-limit")
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	if !wait {
+		maxCheckLimit = 1
+	}
+	statuses, err := getArtifactStatuses(packageId, idPattern, delayLength, maxCheckLimit, exe)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = cmd.OutOrStdout()
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		defer f.Close()
+		w = f
+	}
+	err = writeStatus(w, statuses, output)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		log.Info().Msgf("Status of %d artifact(s) written to %v", len(statuses), outputFile)
+	}
+	return nil
+}
+
+// getArtifactStatuses returns the status of the runtime artifacts that match the package and ID pattern, sorted by ID.
+// The runtime artifacts are retrieved up to maxCheckLimit times until none of them is in STARTING status.
+func getArtifactStatuses(packageId string, idPattern string, delayLength int, maxCheckLimit int, exe *httpclnt.HTTPExecuter) ([]*artifactStatus, error) {
+	// Designtime artifacts of the package, to limit the runtime artifacts and to list those not deployed
+	var packageArtifacts map[string]*api.ArtifactDetails
+	if packageId != "" {
+		artifacts, err := api.NewIntegrationPackage(exe).GetAllArtifacts(packageId)
+		if err != nil {
+			return nil, err
+		}
+		packageArtifacts = map[string]*api.ArtifactDetails{}
+		for _, artifact := range artifacts {
+			if matchesPattern(artifact.Id, idPattern) {
+				packageArtifacts[artifact.Id] = artifact
+			}
+		}
+	}
+
+	runtimeArtifacts, err := getRuntimeArtifacts(packageArtifacts, idPattern, delayLength, maxCheckLimit, api.NewRuntime(exe))
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*artifactStatus
+	deployed := map[string]bool{}
+	for _, artifact := range runtimeArtifacts {
+		deployed[artifact.Id] = true
+		status := &artifactStatus{
+			Id:             artifact.Id,
+			Name:           artifact.Name,
+			Type:           artifact.DesigntimeType(),
+			RuntimeVersion: artifact.Version,
+			Status:         artifact.Status,
+			DeployedBy:     artifact.DeployedBy,
+		}
+		if !artifact.DeployedOn.IsZero() {
+			status.DeployedOn = artifact.DeployedOn.Format(time.RFC3339)
+		}
+		if status.Type == "" {
+			status.Type = artifact.Type
+		}
+		if packageArtifact, ok := packageArtifacts[artifact.Id]; ok {
+			status.DesigntimeVersion = packageArtifact.Version
+		} else if dt := api.NewDesigntimeArtifact(artifact.DesigntimeType(), exe); dt != nil {
+			version, _, exists, err := dt.Get(artifact.Id, "active")
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				status.DesigntimeVersion = version
+			}
+		}
+		status.State = compareVersions(status.RuntimeVersion, status.DesigntimeVersion)
+		statuses = append(statuses, status)
+	}
+	for id, artifact := range packageArtifacts {
+		if !deployed[id] {
+			statuses = append(statuses, &artifactStatus{
+				Id:                id,
+				Name:              artifact.Name,
+				Type:              artifact.ArtifactType,
+				DesigntimeVersion: artifact.Version,
+				State:             stateNotDeployed,
+			})
+		}
+	}
+	slices.SortFunc(statuses, func(a, b *artifactStatus) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return statuses, nil
+}
+
+// getRuntimeArtifacts returns the runtime artifacts that are in the package artifacts (if provided) and match the ID
+// pattern. The check is repeated up to maxCheckLimit times while any of them is still starting.
+func getRuntimeArtifacts(packageArtifacts map[string]*api.ArtifactDetails, idPattern string, delayLength int, maxCheckLimit int, rt *api.Runtime) ([]*api.RuntimeArtifact, error) {
+	for i := 0; ; i++ {
+		artifacts, err := rt.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		var filtered, starting []*api.RuntimeArtifact
+		for _, artifact := range artifacts {
+			if packageArtifacts != nil && packageArtifacts[artifact.Id] == nil {
+				continue
+			}
+			if !matchesPattern(artifact.Id, idPattern) {
+				continue
+			}
+			filtered = append(filtered, artifact)
+			if artifact.Status == "STARTING" {
+				starting = append(starting, artifact)
+			}
+		}
+		if len(starting) == 0 || i >= maxCheckLimit-1 {
+			if len(starting) > 0 && maxCheckLimit > 1 {
+				log.Warn().Msgf("%d artifact(s) still in STARTING status after %d checks", len(starting), maxCheckLimit)
+			}
+			return filtered, nil
+		}
+		log.Info().Msgf("Check %d - %d artifact(s) in STARTING status, e.g. %v", i+1, len(starting), starting[0].Id)
+		time.Sleep(time.Duration(delayLength) * time.Second)
+	}
+}
+
+func matchesPattern(id string, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, id)
+	return matched
+}
+
+// compareVersions returns the state of the runtime version compared to the designtime version
+func compareVersions(runtimeVersion string, designtimeVersion string) string {
+	switch {
+	case designtimeVersion == "":
+		return stateNoDesigntime
+	case designtimeVersion == "Active":
+		// Designtime artifact is in draft, so the deployed version cannot be compared
+		return stateDraft
+	case runtimeVersion == designtimeVersion:
+		return stateUpToDate
+	default:
+		return stateOutdated
+	}
+}
+
+// writeStatus writes the artifact statuses to w in the output format
+func writeStatus(w io.Writer, statuses []*artifactStatus, output string) error {
+	switch output {
+	case outputJSON:
+		if statuses == nil {
+			statuses = []*artifactStatus{}
+		}
+		content, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"id", "name", "type", "runtimeVersion", "designtimeVersion", "status", "state", "deployedBy", "deployedOn"})
+		for _, s := range statuses {
+			_ = cw.Write([]string{s.Id, s.Name, s.Type, s.RuntimeVersion, s.DesigntimeVersion, s.Status, s.State, s.DeployedBy, s.DeployedOn})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return errors.Wrap(err, 0)
+		}
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTYPE\tRUNTIME VERSION\tDESIGNTIME VERSION\tSTATUS\tSTATE\tDEPLOYED BY\tDEPLOYED ON")
+		for _, s := range statuses {
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", s.Id, s.Type, dash(s.RuntimeVersion), dash(s.DesigntimeVersion), dash(s.Status), s.State, dash(s.DeployedBy), dash(s.DeployedOn))
+		}
+		if err := tw.Flush(); err != nil {
+			return errors.Wrap(err, 0)
+		}
+	default:
+		return fmt.Errorf("invalid output format %v", output)
+	}
+	return nil
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetArtifactStatuses(t *testing.T) {
+	// Set up local server with mock HTTP responses
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationRuntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [
+  { "Id": "Order_IFlow", "Version": "1.0.1", "Type": "INTEGRATION_FLOW", "DeployedBy": "user1", "DeployedOn": "/Date(1700000000000)/", "Status": "STARTED" },
+  { "Id": "Order_VM", "Version": "1.0.0", "Type": "VALUE_MAPPING", "Status": "STARTED" },
+  { "Id": "Other_IFlow", "Version": "2.0.0", "Type": "INTEGRATION_FLOW", "Status": "STARTED" }
+] } }`))
+	})
+	mux.HandleFunc("/api/v1/IntegrationDesigntimeArtifacts(Id='Order_IFlow',Version='active')", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "Version": "1.0.2" } }`))
+	})
+	mux.HandleFunc("/api/v1/ValueMappingDesigntimeArtifacts(Id='Order_VM',Version='active')", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/v1/IntegrationPackages('Orders')/IntegrationDesigntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "d": { "results": [ { "Id": "Order_IFlow", "Version": "1.0.1" }, { "Id": "Order_Inbound", "Version": "1.0.0" } ] } }`))
+	})
+	for _, artifactType := range []string{"MessageMapping", "ScriptCollection", "ValueMapping"} {
+		mux.HandleFunc("/api/v1/IntegrationPackages('Orders')/"+artifactType+"DesigntimeArtifacts", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{ "d": { "results": [] } }`))
+		})
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %v", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	exe, _ := httpclnt.NewMockExecuter(t, mux)
+
+	t.Run("IdPattern", func(t *testing.T) {
+		statuses, err := getArtifactStatuses("", "Order_*", 0, 1, exe)
+
+		assert.NoError(t, err)
+		if assert.Equal(t, 2, len(statuses), "Expected number of statuses = 2") {
+			assert.Equal(t, "Order_IFlow", statuses[0].Id)
+			assert.Equal(t, "Integration", statuses[0].Type)
+			assert.Equal(t, "1.0.2", statuses[0].DesigntimeVersion)
+			assert.Equal(t, stateOutdated, statuses[0].State)
+			assert.Equal(t, "2023-11-14T22:13:20Z", statuses[0].DeployedOn)
+			assert.Equal(t, "Order_VM", statuses[1].Id)
+			assert.Equal(t, stateNoDesigntime, statuses[1].State)
+		}
+	})
+
+	t.Run("Package", func(t *testing.T) {
+		statuses, err := getArtifactStatuses("Orders", "", 0, 1, exe)
+
+		assert.NoError(t, err)
+		if assert.Equal(t, 2, len(statuses), "Expected number of statuses = 2") {
+			assert.Equal(t, "Order_IFlow", statuses[0].Id)
+			assert.Equal(t, stateUpToDate, statuses[0].State)
+			assert.Equal(t, "Order_Inbound", statuses[1].Id)
+			assert.Equal(t, stateNotDeployed, statuses[1].State)
+			assert.Equal(t, "", statuses[1].RuntimeVersion)
+		}
+	})
+}
+
+func TestWriteStatus_CSV(t *testing.T) {
+	statuses := []*artifactStatus{
+		{Id: "IFlow1", Type: "Integration", RuntimeVersion: "1.0.0", DesigntimeVersion: "1.0.1", Status: "STARTED", State: stateOutdated},
+	}
+	var buf bytes.Buffer
+
+	err := writeStatus(&buf, statuses, outputCSV)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "id,name,type,runtimeVersion,designtimeVersion,status,state,deployedBy,deployedOn\nIFlow1,,Integration,1.0.0,1.0.1,STARTED,OUTDATED,,\n", buf.String())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -55,6 +56,11 @@ tenant and a Git repository.`,
 					return fmt.Errorf("--dir-artifacts [%v] should be a subdirectory of --dir-git-repo [%v]", artifactsDir, gitRepoDirClean)
 				}
 			}
+			// Validate value mapping format
+			valueMappingFormat := config.GetString(cmd, "value-mapping-format")
+			if valueMappingFormat != "" && !slices.Contains(file.ValueMappingFormats, valueMappingFormat) {
+				return fmt.Errorf("invalid value for --value-mapping-format = %v", valueMappingFormat)
+			}
 			// Validate target
 			target := config.GetString(cmd, "target")
 			switch target {
@@ -66,7 +72,7 @@ tenant and a Git repository.`,
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			startTime := time.Now()
-			if err = runSync(cmd); err != nil {
+			if err = writeReport(cmd, runSync(cmd)); err != nil {
 				cmd.SilenceUsage = true
 			}
 			analytics.Log(cmd, err, startTime)
@@ -90,6 +96,10 @@ tenant and a Git repository.`,
 	syncCmd.Flags().StringSlice("script-collection-map", nil, "Comma-separated source-target ID pairs for converting script collection references during sync ")
 	syncCmd.PersistentFlags().Bool("git-skip-commit", false, "Skip committing changes to Git repository")
 	syncCmd.Flags().Bool("sync-package-details", false, "Sync details of Integration Package")
+	syncCmd.Flags().String("environment", "", "Environment (e.g. QA, PRD) whose parameters.prop overlays are applied when syncing to tenant")
+	syncCmd.Flags().String("value-mapping-format", "", "Format of value mappings in Git. Allowed values: xml, csv, yaml (default is the existing format in Git, or xml)")
+	syncCmd.PersistentFlags().Bool("prune", false, "Delete artifacts in target that no longer exist in source")
+	syncCmd.PersistentFlags().Bool("dry-run", false, dryRunUsage)
 
 	_ = syncCmd.MarkFlagRequired("package-id")
 	_ = syncCmd.MarkFlagRequired("dir-git-repo")
@@ -125,11 +135,20 @@ func runSync(cmd *cobra.Command) error {
 	skipCommit := config.GetBool(cmd, "git-skip-commit")
 	syncPackageLevelDetails := config.GetBool(cmd, "sync-package-details")
 	target := config.GetString(cmd, "target")
+	environment := config.GetString(cmd, "environment")
+	prune := config.GetBool(cmd, "prune")
+	valueMappingFormat := config.GetString(cmd, "value-mapping-format")
 
 	serviceDetails := api.GetServiceDetails(cmd)
 	// Initialise HTTP executer
 	exe := api.InitHTTPExecuter(serviceDetails)
 	synchroniser := sync.New(exe)
+	synchroniser.SetEnvironment(environment)
+	synchroniser.SetPrune(prune)
+	synchroniser.SetValueMappingFormat(valueMappingFormat)
+	dryRunPlan := getDryRunPlan(cmd)
+	synchroniser.SetDryRun(dryRunPlan)
+	synchroniser.SetReport(getReport(cmd))
 
 	// Sync from tenant to Git
 	if target == "git" {
@@ -150,7 +169,7 @@ func runSync(cmd *cobra.Command) error {
 				return err
 			}
 
-			if !skipCommit {
+			if !skipCommit && dryRunPlan == nil {
 				err = repo.CommitToRepo(gitRepoDir, commitMsg, commitUser, commitEmail)
 				if err != nil {
 					return err
@@ -168,7 +187,7 @@ func runSync(cmd *cobra.Command) error {
 			packageFile := fmt.Sprintf("%v/%v.json", artifactsDir, packageId)
 			if file.Exists(packageFile) {
 				packageSynchroniser := sync.NewSyncer("tenant", "CPIPackage", exe)
-				err = packageSynchroniser.Exec(sync.Request{PackageFile: packageFile})
+				err = packageSynchroniser.Exec(sync.Request{PackageFile: packageFile, Plan: dryRunPlan, Report: getReport(cmd)})
 				if err != nil {
 					return err
 				}
@@ -185,5 +204,8 @@ func runSync(cmd *cobra.Command) error {
 			return err
 		}
 	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
 	return nil
 }
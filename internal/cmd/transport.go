@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/engswee/flashpipe/internal/sync"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// transportNaming is the prefix and suffix added to the IDs and names of the transported package and artifacts
+type transportNaming struct {
+	idPrefix   string
+	idSuffix   string
+	namePrefix string
+	nameSuffix string
+}
+
+func (n transportNaming) id(id string) string {
+	return n.idPrefix + id + n.idSuffix
+}
+
+func (n transportNaming) name(name string) string {
+	return n.namePrefix + name + n.nameSuffix
+}
+
+func NewTransportCommand() *cobra.Command {
+	transportCmd := &cobra.Command{
+		Use:   "transport",
+		Short: "Transport integration package between tenants",
+		Long: `Transport an integration package and its artifacts from the source
+tenant (--tmn-host or --profile) to the target tenant (--target-tmn-host
+or --target-profile) without a Git repository. IDs and names can be
+changed with a prefix or suffix, the environment specific parameters
+are applied, and the artifacts are optionally deployed on the target
+tenant.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Validate Draft Handling
+			draftHandling := config.GetString(cmd, "draft-handling")
+			switch draftHandling {
+			case "SKIP", "ADD", "ERROR":
+			default:
+				return fmt.Errorf("invalid value for --draft-handling = %v", draftHandling)
+			}
+			if err := validateAuthFlags(cmd, "target-"); err != nil {
+				return err
+			}
+			if config.GetString(cmd, "environment") != "" && config.GetString(cmd, "dir-parameters") == "" {
+				return fmt.Errorf("--dir-parameters is required when --environment is set")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runTransport(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	transportCmd.Flags().String("package-id", "", "ID of integration package in source tenant")
+	transportCmd.Flags().String("target-profile", "", "Name of tenant profile in the profiles section of the config file used as target tenant")
+	transportCmd.Flags().String("target-tmn-host", "", "Host for tenant management node of target tenant excluding https://")
+	transportCmd.Flags().String("target-tmn-userid", "", "User ID for Basic Auth of target tenant")
+	transportCmd.Flags().String("target-tmn-password", "", "Password for Basic Auth of target tenant")
+	transportCmd.Flags().String("target-oauth-host", "", "Host for OAuth token server of target tenant excluding https://")
+	transportCmd.Flags().String("target-oauth-clientid", "", "Client ID for using OAuth of target tenant")
+	transportCmd.Flags().String("target-oauth-clientsecret", "", "Client Secret for using OAuth of target tenant")
+	transportCmd.Flags().String("target-oauth-path", "/oauth/token", "Path for OAuth token server of target tenant")
+	transportCmd.Flags().String("target-client-cert", "", "PEM or PKCS#12 (.p12, .pfx) file with client certificate for target tenant")
+	transportCmd.Flags().String("target-client-key", "", "PEM file with private key of client certificate for target tenant")
+	transportCmd.Flags().String("target-client-cert-passphrase", "", "Passphrase of client certificate for target tenant")
+	transportCmd.Flags().String("target-ca-bundle", "", "PEM file with CA certificates trusted for target tenant")
+	transportCmd.Flags().StringSlice("ids-include", nil, "List of included artifact IDs")
+	transportCmd.Flags().StringSlice("ids-exclude", nil, "List of excluded artifact IDs")
+	transportCmd.Flags().String("draft-handling", "SKIP", "Handling when artifact is in draft version. Allowed values: SKIP, ADD, ERROR")
+	transportCmd.Flags().String("id-prefix", "", "Prefix added to the IDs of package and artifacts in target tenant")
+	transportCmd.Flags().String("id-suffix", "", "Suffix added to the IDs of package and artifacts in target tenant")
+	transportCmd.Flags().String("name-prefix", "", "Prefix added to the names of package and artifacts in target tenant")
+	transportCmd.Flags().String("name-suffix", "", "Suffix added to the names of package and artifacts in target tenant")
+	transportCmd.Flags().StringSlice("script-collection-map", nil, "Comma-separated source-target ID pairs for converting script collection references during transport")
+	transportCmd.Flags().String("environment", "", "Environment (e.g. QA, PRD) whose parameters.prop overlays in --dir-parameters are applied in target tenant")
+	transportCmd.Flags().String("dir-parameters", "", "Directory containing <environment>/parameters.prop and <artifact ID>/<environment>/parameters.prop overlays")
+	transportCmd.Flags().String("dir-work", "/tmp", "Working directory for in-transit files")
+	transportCmd.Flags().Bool("deploy", false, "Deploy the artifacts of the package in target tenant after transport")
+	transportCmd.Flags().Int("delay-length", 30, "Delay (in seconds) between each check of artifact deployment status")
+	transportCmd.Flags().Int("max-check-limit", 10, "Max number of times to check for artifact deployment status")
+	transportCmd.Flags().Int("parallelism", 1, "Number of artifacts to deploy and check concurrently")
+	transportCmd.Flags().Bool("dry-run", false, dryRunUsage)
+
+	_ = transportCmd.MarkFlagRequired("package-id")
+	_ = transportCmd.MarkFlagRequired("target-tmn-host")
+	transportCmd.MarkFlagsRequiredTogether("target-tmn-userid", "target-tmn-password")
+	transportCmd.MarkFlagsRequiredTogether("target-oauth-host", "target-oauth-clientid")
+	transportCmd.MarkFlagsMutuallyExclusive("ids-include", "ids-exclude")
+
+	return transportCmd
+}
+
+func runTransport(cmd *cobra.Command) error {
+	log.Info().Msg("Executing transport command")
+
+	packageId := config.GetString(cmd, "package-id")
+	includedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-include"))
+	excludedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-exclude"))
+	draftHandling := config.GetString(cmd, "draft-handling")
+	naming := transportNaming{
+		idPrefix:   config.GetString(cmd, "id-prefix"),
+		idSuffix:   config.GetString(cmd, "id-suffix"),
+		namePrefix: config.GetString(cmd, "name-prefix"),
+		nameSuffix: config.GetString(cmd, "name-suffix"),
+	}
+	scriptCollectionMap := str.TrimSlice(config.GetStringSlice(cmd, "script-collection-map"))
+	environment := config.GetString(cmd, "environment")
+	parametersDir, err := config.GetStringWithEnvExpand(cmd, "dir-parameters")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-parameters: %w", err)
+	}
+	workDir, err := config.GetStringWithEnvExpand(cmd, "dir-work")
+	if err != nil {
+		return fmt.Errorf("security alert for --dir-work: %w", err)
+	}
+	dryRunPlan := getDryRunPlan(cmd)
+	rep := getReport(cmd)
+
+	// In-transit files are kept in a separate directory so that the working directory of other commands is not affected
+	transportDir := fmt.Sprintf("%v/transport", workDir)
+	artifactsDir := fmt.Sprintf("%v/artifacts", transportDir)
+	err = os.RemoveAll(transportDir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	err = os.MkdirAll(artifactsDir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	defer os.RemoveAll(transportDir)
+
+	// Download package and artifacts from source tenant
+	srcExe := api.InitHTTPExecuter(api.GetServiceDetails(cmd))
+	srcSynchroniser := sync.New(srcExe)
+	packageData, readOnly, _, err := srcSynchroniser.VerifyDownloadablePackage(packageId)
+	if err != nil {
+		return err
+	}
+	if readOnly {
+		return fmt.Errorf("Package %v is Configure-only and cannot be transported", packageId)
+	}
+	err = srcSynchroniser.ArtifactsToGit(packageId, transportDir, artifactsDir, includedIds, excludedIds, draftHandling, "ID", scriptCollectionMap)
+	if err != nil {
+		return err
+	}
+
+	// Change IDs and names for target tenant
+	targetPackageId := naming.id(packageId)
+	packageFile := fmt.Sprintf("%v/%v.json", transportDir, targetPackageId)
+	err = writeTransportPackage(packageData, naming, packageFile)
+	if err != nil {
+		return err
+	}
+	transported, err := renameTransportArtifacts(artifactsDir, naming)
+	if err != nil {
+		return err
+	}
+	if environment != "" {
+		err = copyTransportParameters(parametersDir, environment, artifactsDir, transported)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Upload package and artifacts to target tenant
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("📢 Uploading integration package %v to target tenant", targetPackageId)
+	tgtExe := api.InitHTTPExecuter(api.GetServiceDetailsWithPrefix(cmd, "target-"))
+	tgtSynchroniser := sync.New(tgtExe)
+	tgtSynchroniser.SetEnvironment(environment)
+	tgtSynchroniser.SetDryRun(dryRunPlan)
+	tgtSynchroniser.SetReport(rep)
+	_, _, targetPackageExists, err := api.NewIntegrationPackage(tgtExe).Get(targetPackageId)
+	if err != nil {
+		return err
+	}
+	err = sync.NewSyncer("tenant", "CPIPackage", tgtExe).Exec(sync.Request{PackageFile: packageFile, Plan: dryRunPlan, Report: rep})
+	if err != nil {
+		return err
+	}
+	err = tgtSynchroniser.ArtifactsToTenant(targetPackageId, transportDir, artifactsDir, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// Deploy artifacts in target tenant
+	var deployed []string
+	if config.GetBool(cmd, "deploy") {
+		if dryRunPlan != nil && !targetPackageExists {
+			// The artifacts of a package that is not created yet cannot be determined
+			dryRunPlan.Add(plan.Deploy, "package", targetPackageId, "tenant", "all artifacts of package")
+		} else {
+			deployed, err = deployPackage(targetPackageId, draftHandling, transportDir, config.GetInt(cmd, "delay-length"), config.GetInt(cmd, "max-check-limit"), true, config.GetInt(cmd, "parallelism"), "", tgtExe, dryRunPlan, rep)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+		return nil
+	}
+	logTransportSummary(packageId, targetPackageId, transported, deployed)
+	return nil
+}
+
+// writeTransportPackage writes the package details with the ID and name for the target tenant to the file
+func writeTransportPackage(packageData *api.PackageSingleData, naming transportNaming, packageFile string) error {
+	packageData.Root.Id = naming.id(packageData.Root.Id)
+	packageData.Root.Name = naming.name(packageData.Root.Name)
+	content, err := json.MarshalIndent(packageData, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	err = os.WriteFile(packageFile, content, 0644)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
+
+// renameTransportArtifacts changes the IDs and names in MANIFEST.MF of the downloaded artifacts. The target ID of each
+// artifact is returned with the source ID, which is the name of the artifact directory, as the key.
+func renameTransportArtifacts(artifactsDir string, naming transportNaming) (map[string]string, error) {
+	entries, err := os.ReadDir(artifactsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	transported := map[string]string{}
+	for _, entry := range entries {
+		manifestPath := fmt.Sprintf("%v/%v/META-INF/MANIFEST.MF", artifactsDir, entry.Name())
+		if !entry.IsDir() || !file.Exists(manifestPath) {
+			continue
+		}
+		// The symbolic name can contain directives after the ID, e.g. ;singleton:=true
+		symbolicName, err := file.GetManifestHeader(manifestPath, "Bundle-SymbolicName")
+		if err != nil {
+			return nil, err
+		}
+		id, directives, _ := strings.Cut(strings.ReplaceAll(symbolicName, " ", ""), ";")
+		targetId := naming.id(id)
+		if directives != "" {
+			targetId += ";" + directives
+		}
+		name, err := file.GetManifestHeader(manifestPath, "Bundle-Name")
+		if err != nil {
+			return nil, err
+		}
+		err = file.SetManifestHeader(manifestPath, "Bundle-SymbolicName", targetId)
+		if err != nil {
+			return nil, err
+		}
+		err = file.SetManifestHeader(manifestPath, "Bundle-Name", naming.name(name))
+		if err != nil {
+			return nil, err
+		}
+		transported[entry.Name()] = naming.id(id)
+	}
+	return transported, nil
+}
+
+// copyTransportParameters copies the package and artifact level parameters.prop overlays of the environment to the
+// locations used when syncing to the tenant
+func copyTransportParameters(parametersDir string, environment string, artifactsDir string, transported map[string]string) error {
+	files := map[string]string{
+		fmt.Sprintf("%v/%v/parameters.prop", parametersDir, environment): fmt.Sprintf("%v/%v/parameters.prop", artifactsDir, environment),
+	}
+	for sourceId := range transported {
+		files[fmt.Sprintf("%v/%v/%v/parameters.prop", parametersDir, sourceId, environment)] = fmt.Sprintf("%v/%v/%v/parameters.prop", artifactsDir, sourceId, environment)
+	}
+	for src, dst := range files {
+		if !file.Exists(src) {
+			continue
+		}
+		log.Info().Msgf("Applying parameters from %v", src)
+		err := file.CopyFile(src, dst)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func logTransportSummary(packageId string, targetPackageId string, transported map[string]string, deployed []string) {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("🏆 Transport of integration package %v to %v completed with %d artifact(s)", packageId, targetPackageId, len(transported))
+	sourceIds := make([]string, 0, len(transported))
+	for sourceId := range transported {
+		sourceIds = append(sourceIds, sourceId)
+	}
+	slices.Sort(sourceIds)
+	for _, sourceId := range sourceIds {
+		log.Info().Msgf("  %v -> %v", sourceId, transported[sourceId])
+	}
+	if len(deployed) > 0 {
+		log.Info().Msgf("🏆 Deployed integration flow(s) %v", strings.Join(deployed, ", "))
+	}
+}
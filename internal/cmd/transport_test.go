@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTransportArtifact(t *testing.T, artifactsDir string, id string, name string) string {
+	manifestPath := filepath.Join(artifactsDir, id, "META-INF", "MANIFEST.MF")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(manifestPath), os.ModePerm))
+	content := "Manifest-Version: 1.0\nBundle-SymbolicName: " + id + "; singleton:=true\nBundle-Name: " + name + "\nBundle-Version: 1.0.0\n\n"
+	assert.NoError(t, os.WriteFile(manifestPath, []byte(content), 0644))
+	return manifestPath
+}
+
+func TestRenameTransportArtifacts(t *testing.T) {
+	artifactsDir := t.TempDir()
+	manifestPath := writeTransportArtifact(t, artifactsDir, "IFlow1", "Integration Flow 1")
+	assert.NoError(t, os.MkdirAll(filepath.Join(artifactsDir, "QA"), os.ModePerm))
+
+	naming := transportNaming{idPrefix: "QA_", idSuffix: "_v2", namePrefix: "[QA] "}
+	transported, err := renameTransportArtifacts(artifactsDir, naming)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"IFlow1": "QA_IFlow1_v2"}, transported)
+
+	symbolicName, err := file.GetManifestHeader(manifestPath, "Bundle-SymbolicName")
+	assert.NoError(t, err)
+	assert.Equal(t, "QA_IFlow1_v2;singleton:=true", symbolicName)
+	name, err := file.GetManifestHeader(manifestPath, "Bundle-Name")
+	assert.NoError(t, err)
+	assert.Equal(t, "[QA] Integration Flow 1", name)
+}
+
+func TestCopyTransportParameters(t *testing.T) {
+	parametersDir := t.TempDir()
+	artifactsDir := t.TempDir()
+	for _, f := range []string{"QA/parameters.prop", "IFlow1/QA/parameters.prop", "IFlow1/PRD/parameters.prop", "IFlow2/QA/parameters.prop"} {
+		path := filepath.Join(parametersDir, f)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assert.NoError(t, os.WriteFile(path, []byte("Key=Value\n"), 0644))
+	}
+
+	err := copyTransportParameters(parametersDir, "QA", artifactsDir, map[string]string{"IFlow1": "QA_IFlow1"})
+	assert.NoError(t, err)
+
+	assert.True(t, file.Exists(filepath.Join(artifactsDir, "QA", "parameters.prop")))
+	assert.True(t, file.Exists(filepath.Join(artifactsDir, "IFlow1", "QA", "parameters.prop")))
+	assert.False(t, file.Exists(filepath.Join(artifactsDir, "IFlow1", "PRD", "parameters.prop")))
+	assert.False(t, file.Exists(filepath.Join(artifactsDir, "IFlow2")))
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/analytics"
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/config"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewUndeployCommand() *cobra.Command {
+
+	undeployCmd := &cobra.Command{
+		Use:   "undeploy",
+		Short: "Undeploy runtime artifacts",
+		Long: `Undeploy artifacts from the runtime of
+SAP Integration Suite tenant.`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			startTime := time.Now()
+			if err = writeReport(cmd, runUndeploy(cmd)); err != nil {
+				cmd.SilenceUsage = true
+			}
+			analytics.Log(cmd, err, startTime)
+			return
+		},
+	}
+
+	// Define cobra flags, the default value has the lowest (least significant) precedence
+	undeployCmd.Flags().StringSlice("artifact-ids", nil, "Comma separated list of artifact IDs")
+	undeployCmd.Flags().String("package-id", "", "ID of integration package whose artifacts are undeployed")
+	undeployCmd.Flags().StringSlice("ids-include", nil, "List of included artifact IDs")
+	undeployCmd.Flags().StringSlice("ids-exclude", nil, "List of excluded artifact IDs")
+	undeployCmd.Flags().Int("delay-length", 30, "Delay (in seconds) between each check of artifact undeployment status")
+	undeployCmd.Flags().Int("max-check-limit", 10, "Max number of times to check for artifact undeployment status")
+	undeployCmd.Flags().Bool("dry-run", false, dryRunUsage)
+
+	undeployCmd.MarkFlagsOneRequired("artifact-ids", "package-id")
+	undeployCmd.MarkFlagsMutuallyExclusive("artifact-ids", "package-id")
+	undeployCmd.MarkFlagsMutuallyExclusive("ids-include", "ids-exclude")
+	return undeployCmd
+}
+
+func runUndeploy(cmd *cobra.Command) error {
+	log.Info().Msg("Executing undeploy command")
+
+	artifactIds := str.TrimSlice(config.GetStringSlice(cmd, "artifact-ids"))
+	packageId := config.GetString(cmd, "package-id")
+	includedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-include"))
+	excludedIds := str.TrimSlice(config.GetStringSlice(cmd, "ids-exclude"))
+	delayLength := config.GetInt(cmd, "delay-length")
+	maxCheckLimit := config.GetInt(cmd, "max-check-limit")
+	dryRunPlan := getDryRunPlan(cmd)
+
+	// Initialise HTTP executer
+	serviceDetails := api.GetServiceDetails(cmd)
+	exe := api.InitHTTPExecuter(serviceDetails)
+
+	if packageId != "" {
+		artifacts, err := api.NewIntegrationPackage(exe).GetAllArtifacts(packageId)
+		if err != nil {
+			return err
+		}
+		for _, artifact := range artifacts {
+			artifactIds = append(artifactIds, artifact.Id)
+		}
+	}
+	var filteredIds []string
+	for _, id := range artifactIds {
+		if !str.FilterIDs(id, includedIds, excludedIds) {
+			filteredIds = append(filteredIds, id)
+		}
+	}
+	if len(filteredIds) == 0 {
+		log.Warn().Msg("No artifacts to undeploy")
+		return nil
+	}
+
+	err := undeployArtifacts(filteredIds, delayLength, maxCheckLimit, exe, dryRunPlan, getReport(cmd))
+	if err != nil {
+		return err
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Log()
+	}
+	return nil
+}
+
+// undeployArtifacts undeploys each runtime artifact and waits until it is removed from the runtime. All artifacts are
+// processed even if some of them fail, and the failures are summarised at the end.
+func undeployArtifacts(artifactIds []string, delayLength int, maxCheckLimit int, exe *httpclnt.HTTPExecuter, dryRunPlan *plan.Plan, rep *report.Report) error {
+	rt := api.NewRuntime(exe)
+
+	var failures []string
+	for i, id := range artifactIds {
+		log.Info().Msg("---------------------------------------------------------------------------------")
+		log.Info().Msgf("Processing artifact %d - %v", i+1, id)
+		start := time.Now()
+		entry := report.Entry{ArtifactType: "runtime artifact", Id: id, Target: "tenant"}
+		version, err := undeploySingle(rt, id, delayLength, maxCheckLimit, dryRunPlan)
+		entry.VersionBefore = version
+		if err != nil {
+			log.Error().Msgf("Artifact %d - %v undeployment failed: %v", i+1, id, err)
+			failures = append(failures, fmt.Sprintf("%v: %v", id, err))
+			rep.AddError(start, entry, err)
+			continue
+		}
+		if version == "NOT_DEPLOYED" {
+			entry.Action = report.Skipped
+			entry.Message = "not deployed"
+		} else {
+			entry.Action = report.Undeployed
+		}
+		rep.Add(start, entry)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d artifact(s) failed to undeploy\n%v", len(failures), len(artifactIds), strings.Join(failures, "\n"))
+	}
+	log.Info().Msg("🏆 Artifact(s) undeployment completed successfully")
+	return nil
+}
+
+// undeploySingle undeploys the runtime artifact, and returns the version that was deployed
+func undeploySingle(rt *api.Runtime, id string, delayLength int, maxCheckLimit int, dryRunPlan *plan.Plan) (string, error) {
+	version, status, err := rt.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if version == "NOT_DEPLOYED" {
+		log.Info().Msgf("Artifact %v is not deployed. Skipping runtime undeployment", id)
+		return version, nil
+	}
+	if version == "" {
+		// Artifacts that failed to start have no version
+		version = status
+	}
+	if dryRunPlan != nil {
+		dryRunPlan.Add(plan.Undeploy, "runtime artifact", id, "tenant", fmt.Sprintf("version %v", version))
+		return version, nil
+	}
+	err = rt.UnDeploy(id)
+	if err != nil {
+		return version, err
+	}
+	log.Info().Msgf("Artifact %v undeployment triggered", id)
+	return version, checkUndeploymentStatus(rt, delayLength, maxCheckLimit, id)
+}
+
+func checkUndeploymentStatus(runtime *api.Runtime, delayLength int, maxCheckLimit int, id string) error {
+	log.Info().Msgf("Checking runtime status for artifact %v every %d seconds up to %d times", id, delayLength, maxCheckLimit)
+
+	for i := 0; i < maxCheckLimit; i++ {
+		version, status, err := runtime.Get(id)
+		if err != nil {
+			return err
+		}
+		if version == "NOT_DEPLOYED" {
+			log.Info().Msgf("🏆 Artifact %v undeployed successfully", id)
+			return nil
+		}
+		log.Info().Msgf("Check %d - Current artifact runtime status = %s", i+1, status)
+		if i < maxCheckLimit-1 {
+			time.Sleep(time.Duration(delayLength) * time.Second)
+		}
+	}
+	return fmt.Errorf("Artifact still deployed after %d checks", maxCheckLimit)
+}
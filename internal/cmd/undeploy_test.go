@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUndeployArtifacts(t *testing.T) {
+	var mu sync.Mutex
+	deployed := map[string]bool{"IFlow1": true, "IFlow2": true, "IFlow3": true}
+	failed := map[string]bool{"IFlow2": true}
+
+	runtimePath := regexp.MustCompile(`^/api/v1/IntegrationRuntimeArtifacts\('(.+)'\)$`)
+
+	// Set up local server with mock HTTP responses
+	exe, _ := httpclnt.NewMockExecuter(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := r.URL.Path
+		switch {
+		case path == "/api/v1/":
+			w.Header().Set("x-csrf-token", "token123")
+		case runtimePath.MatchString(path):
+			id := runtimePath.FindStringSubmatch(path)[1]
+			if r.Method == http.MethodDelete {
+				if failed[id] {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				delete(deployed, id)
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			if !deployed[id] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{ "d": { "Version": "1.0.1", "Status": "STARTED" } }`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	rep := report.New("undeploy")
+	err := undeployArtifacts([]string{"IFlow1", "IFlow2", "IFlow3", "IFlow4"}, 0, 3, exe, nil, rep)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "1 of 4 artifact(s) failed to undeploy")
+	}
+	entries := rep.Entries()
+	if assert.Equal(t, 4, len(entries), "Expected number of report entries = 4") {
+		assert.Equal(t, report.Undeployed, entries[0].Action)
+		assert.Equal(t, "1.0.1", entries[0].VersionBefore)
+		assert.Equal(t, report.Failed, entries[1].Action)
+		assert.Equal(t, report.Undeployed, entries[2].Action)
+		assert.Equal(t, report.Skipped, entries[3].Action)
+	}
+}
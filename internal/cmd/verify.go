@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// smokeTest is the verification of integration flows after they are deployed
+type smokeTest struct {
+	window         time.Duration // Duration to watch message processing logs for failed messages
+	interval       time.Duration // Interval between each check of the message processing logs
+	endpoint       string        // URL of the HTTP endpoint of the integration flow that is called
+	payloadFile    string
+	expectedStatus int
+	exe            *httpclnt.HTTPExecuter // Executer for the HTTP endpoint
+}
+
+// newSmokeTest returns the smoke test for the flags, or nil if neither the window nor the endpoint is set. The
+// endpoint is called with the credentials of the tenant, unless a user ID and password are provided.
+func newSmokeTest(windowSeconds int, intervalSeconds int, endpoint string, payloadFile string, expectedStatus int, userId string, password string, serviceDetails *api.ServiceDetails) (*smokeTest, error) {
+	if windowSeconds <= 0 && endpoint == "" {
+		return nil, nil
+	}
+	st := &smokeTest{
+		window:         time.Duration(windowSeconds) * time.Second,
+		interval:       time.Duration(intervalSeconds) * time.Second,
+		endpoint:       endpoint,
+		payloadFile:    payloadFile,
+		expectedStatus: expectedStatus,
+	}
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid value for --verify-url = %v", endpoint)
+		}
+		port := 443
+		if u.Port() != "" {
+			port, _ = strconv.Atoi(u.Port())
+		} else if u.Scheme == "http" {
+			port = 80
+		}
+		// The endpoint is called with the same client certificate and CA certificates as the tenant
+		if userId != "" {
+			st.exe = httpclnt.NewWithTLS("", "", "", "", userId, password, u.Hostname(), u.Scheme, port, true, serviceDetails.TLSOptions)
+		} else {
+			st.exe = httpclnt.NewWithTLS(serviceDetails.OauthHost, serviceDetails.OauthPath, serviceDetails.OauthClientId, serviceDetails.OauthClientSecret, serviceDetails.Userid, serviceDetails.Password, u.Hostname(), u.Scheme, port, true, serviceDetails.TLSOptions)
+		}
+		if serviceDetails.RetryPolicy != nil {
+			st.exe.SetRetryPolicy(serviceDetails.RetryPolicy)
+		}
+	}
+	return st, nil
+}
+
+// verifyDeployment calls the HTTP endpoint of the integration flow (if set), and then watches the message processing
+// logs of the integration flows for the duration of the window. An error is returned when the endpoint responds with
+// an unexpected status, or a message of the integration flows ends in FAILED or RETRY status.
+func verifyDeployment(iflowIds []string, st *smokeTest, exe *httpclnt.HTTPExecuter) error {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("📢 Verifying deployment of integration flow(s) %v", strings.Join(iflowIds, ", "))
+	start := time.Now()
+
+	if st.endpoint != "" {
+		err := st.callEndpoint()
+		if err != nil {
+			return err
+		}
+	}
+
+	if st.window > 0 && len(iflowIds) > 0 {
+		err := watchMessageLogs(iflowIds, start, st.window, st.interval, api.NewMessageProcessingLogs(exe))
+		if err != nil {
+			return err
+		}
+	}
+	log.Info().Msg("🏆 Deployment verification completed successfully")
+	return nil
+}
+
+// callEndpoint sends the payload file to the endpoint and checks the response status
+func (st *smokeTest) callEndpoint() error {
+	var payload []byte
+	contentType := "text/plain"
+	if st.payloadFile != "" {
+		var err error
+		payload, err = os.ReadFile(st.payloadFile)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+		if t := mime.TypeByExtension(filepath.Ext(st.payloadFile)); t != "" {
+			contentType = t
+		}
+	}
+	log.Info().Msgf("Calling endpoint %v", st.endpoint)
+	u, _ := url.Parse(st.endpoint)
+	resp, err := st.exe.ExecRequestWithCookies(http.MethodPost, u.RequestURI(), bytes.NewReader(payload), map[string]string{"Content-Type": contentType}, nil)
+	if err != nil {
+		return err
+	}
+	respBody, err := st.exe.ReadRespBody(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != st.expectedStatus {
+		log.Debug().Msgf("Response body = %s", respBody)
+		return fmt.Errorf("Smoke test call to %v ended with status %d, expected %d", st.endpoint, resp.StatusCode, st.expectedStatus)
+	}
+	log.Info().Msgf("Smoke test call to %v ended with expected status %d", st.endpoint, resp.StatusCode)
+	return nil
+}
+
+// watchMessageLogs checks the message processing logs of the integration flows since start, every interval until the
+// window has passed. An error is returned as soon as a message in FAILED or RETRY status is found.
+func watchMessageLogs(iflowIds []string, start time.Time, window time.Duration, interval time.Duration, mpl *api.MessageProcessingLogs) error {
+	log.Info().Msgf("Watching message processing logs for %v", window)
+	end := start.Add(window)
+	for {
+		lastCheck := !time.Now().Before(end)
+		var failures []string
+		for _, id := range iflowIds {
+			for _, status := range []string{"FAILED", "RETRY"} {
+				logs, err := mpl.Query(api.MessageLogFilter{IntegrationFlowName: id, Status: status, From: start}, 0, 10)
+				if err != nil {
+					return err
+				}
+				for _, l := range logs {
+					failures = append(failures, fmt.Sprintf("%v: message %v ended in %v status", id, l.MessageGuid, l.Status))
+				}
+			}
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("Deployment verification failed with %d message(s) in error\n%v", len(failures), strings.Join(failures, "\n"))
+		}
+		if lastCheck {
+			return nil
+		}
+		wait := time.Until(end)
+		if interval > 0 && interval < wait {
+			wait = interval
+		}
+		log.Info().Msgf("No failed messages so far, checking again in %v", wait.Round(time.Second))
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDeployment(t *testing.T) {
+	endpointStatus, failedIFlow := http.StatusOK, ""
+
+	// Set up local server with mock HTTP responses
+	mux := http.NewServeMux()
+	mux.HandleFunc("/http/orders", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.WriteHeader(endpointStatus)
+	})
+	mux.HandleFunc("/api/v1/MessageProcessingLogs", func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("$filter")
+		if failedIFlow != "" && strings.Contains(filter, "IntegrationFlowName eq '"+failedIFlow+"'") && strings.Contains(filter, "Status eq 'FAILED'") {
+			w.Write([]byte(`{ "d": { "results": [ { "MessageGuid": "AGW1", "IntegrationFlowName": "` + failedIFlow + `", "Status": "FAILED" } ] } }`))
+			return
+		}
+		w.Write([]byte(`{ "d": { "results": [] } }`))
+	})
+	exe, svr := httpclnt.NewMockExecuter(t, mux)
+
+	t.Run("Successful", func(t *testing.T) {
+		st, err := newSmokeTest(1, 0, svr.URL+"/http/orders", "", http.StatusOK, "", "", &api.ServiceDetails{Userid: "dummyuser", Password: "dummypassword"})
+		assert.NoError(t, err)
+
+		err = verifyDeployment([]string{"IFlow1"}, st, exe)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("FailedMessage", func(t *testing.T) {
+		failedIFlow = "IFlow2"
+		defer func() { failedIFlow = "" }()
+		st, err := newSmokeTest(1, 0, "", "", http.StatusOK, "", "", &api.ServiceDetails{})
+		assert.NoError(t, err)
+
+		err = verifyDeployment([]string{"IFlow1", "IFlow2"}, st, exe)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, "Deployment verification failed with 1 message(s) in error\nIFlow2: message AGW1 ended in FAILED status", err.Error())
+		}
+	})
+
+	t.Run("UnexpectedStatus", func(t *testing.T) {
+		endpointStatus = http.StatusInternalServerError
+		defer func() { endpointStatus = http.StatusOK }()
+		st, err := newSmokeTest(0, 0, svr.URL+"/http/orders", "", http.StatusOK, "", "", &api.ServiceDetails{Userid: "dummyuser", Password: "dummypassword"})
+		assert.NoError(t, err)
+
+		err = verifyDeployment([]string{"IFlow1"}, st, exe)
+
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "ended with status 500, expected 200")
+		}
+	})
+}
+
+func TestNewSmokeTest_ClientCertificate(t *testing.T) {
+	serviceDetails := &api.ServiceDetails{Host: "tenant.hana.ondemand.com", TLSOptions: &httpclnt.TLSOptions{CertFile: "../../test/testdata/certificates/client.p12", Passphrase: "secret"}}
+
+	st, err := newSmokeTest(0, 0, "https://tenant-rt.hana.ondemand.com/http/orders", "", 200, "", "", serviceDetails)
+	assert.NoError(t, err)
+	assert.Equal(t, "CERTIFICATE", st.exe.AuthType, "endpoint should be called with client certificate of tenant")
+}
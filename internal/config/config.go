@@ -67,6 +67,10 @@ func verifyNoSensitiveContent(input string) (bool, error) {
 		"tmn-password",
 		"oauth-clientid",
 		"oauth-clientsecret",
+		"client-cert-passphrase",
+		"verify-password",
+		"secrets-key",
+		"keypair-password",
 	}
 
 	for _, sensContConfigParam := range sensContConfigParams {
@@ -2,10 +2,14 @@ package file
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"strings"
+
 	"github.com/beevik/etree"
 	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
-	"os"
 )
 
 func UpdateBPMN(artifactDir string, scriptMap []string) error {
@@ -70,3 +74,49 @@ func updateXML(filePath string, scripts map[string]string) error {
 	}
 	return nil
 }
+
+// BPMNReferences are the references of an integration flow to other artifacts
+type BPMNReferences struct {
+	ScriptCollections []string // IDs of script collections referenced by scriptBundleId
+	ProcessDirectIn   []string // Addresses of ProcessDirect sender channels, i.e. the flow is called by other flows
+	ProcessDirectOut  []string // Addresses of ProcessDirect receiver channels, i.e. the flow calls other flows
+}
+
+// GetBPMNReferences returns the references of the integration flow BPMN2 XML content. ProcessDirect addresses that
+// contain externalized parameters are ignored as their values are only known at runtime.
+func GetBPMNReferences(content []byte) (*BPMNReferences, error) {
+	doc := etree.NewDocument()
+	err := doc.ReadFromBytes(content)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	refs := new(BPMNReferences)
+	for _, bundles := range doc.FindElements("//ifl:property[key='scriptBundleId']") {
+		value := strings.TrimSpace(bundles.SelectElement("value").Text())
+		if value != "" && !slices.Contains(refs.ScriptCollections, value) {
+			refs.ScriptCollections = append(refs.ScriptCollections, value)
+		}
+	}
+	for _, flow := range doc.FindElements("//bpmn2:messageFlow") {
+		properties := map[string]string{}
+		for _, property := range flow.FindElements("./bpmn2:extensionElements/ifl:property") {
+			key := property.SelectElement("key")
+			value := property.SelectElement("value")
+			if key != nil && value != nil {
+				properties[key.Text()] = strings.TrimSpace(value.Text())
+			}
+		}
+		address := properties["address"]
+		if properties["ComponentType"] != "ProcessDirect" || address == "" || strings.Contains(address, "{{") {
+			continue
+		}
+		switch properties["direction"] {
+		case "Sender":
+			refs.ProcessDirectIn = append(refs.ProcessDirectIn, address)
+		case "Receiver":
+			refs.ProcessDirectOut = append(refs.ProcessDirectOut, address)
+		}
+	}
+	return refs, nil
+}
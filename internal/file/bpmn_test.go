@@ -0,0 +1,51 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const processDirectIFlow = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd" id="Definitions_1">
+    <bpmn2:collaboration id="Collaboration_1" name="Default Collaboration">
+        <bpmn2:messageFlow id="MessageFlow_1" name="ProcessDirect" sourceRef="Participant_1" targetRef="StartEvent_2">
+            <bpmn2:extensionElements>
+                <ifl:property><key>ComponentType</key><value>ProcessDirect</value></ifl:property>
+                <ifl:property><key>address</key><value>/demo/inbound</value></ifl:property>
+                <ifl:property><key>direction</key><value>Sender</value></ifl:property>
+            </bpmn2:extensionElements>
+        </bpmn2:messageFlow>
+        <bpmn2:messageFlow id="MessageFlow_2" name="ProcessDirect" sourceRef="EndEvent_2" targetRef="Participant_2">
+            <bpmn2:extensionElements>
+                <ifl:property><key>ComponentType</key><value>ProcessDirect</value></ifl:property>
+                <ifl:property><key>address</key><value>/demo/outbound</value></ifl:property>
+                <ifl:property><key>direction</key><value>Receiver</value></ifl:property>
+            </bpmn2:extensionElements>
+        </bpmn2:messageFlow>
+        <bpmn2:messageFlow id="MessageFlow_3" name="ProcessDirect" sourceRef="EndEvent_3" targetRef="Participant_3">
+            <bpmn2:extensionElements>
+                <ifl:property><key>ComponentType</key><value>ProcessDirect</value></ifl:property>
+                <ifl:property><key>address</key><value>{{Target_Address}}</value></ifl:property>
+                <ifl:property><key>direction</key><value>Receiver</value></ifl:property>
+            </bpmn2:extensionElements>
+        </bpmn2:messageFlow>
+    </bpmn2:collaboration>
+</bpmn2:definitions>`
+
+func TestGetBPMNReferences_ScriptCollection(t *testing.T) {
+	content, err := os.ReadFile("../../test/testdata/artifacts/collection/IFlow1/src/main/resources/scenarioflows/integrationflow/IFlow1.iflw")
+	assert.NoError(t, err)
+
+	refs, err := GetBPMNReferences(content)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Script1"}, refs.ScriptCollections)
+}
+
+func TestGetBPMNReferences_ProcessDirect(t *testing.T) {
+	refs, err := GetBPMNReferences([]byte(processDirectIFlow))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/demo/inbound"}, refs.ProcessDirectIn)
+	assert.Equal(t, []string{"/demo/outbound"}, refs.ProcessDirectOut, "Externalized addresses should be ignored")
+}
@@ -1,34 +1,508 @@
 package file
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
-	"os/exec"
 )
 
-func DiffDirectories(firstDir string, secondDir string) bool {
-	log.Info().Msgf("Executing command: diff --ignore-matching-lines=^Origin.* --strip-trailing-cr --recursive --ignore-all-space --ignore-blank-lines --exclude=parameters.prop --exclude=.DS_Store %v %v", firstDir, secondDir)
-	cmd := exec.Command("diff", "--ignore-matching-lines=^Origin.*", "--strip-trailing-cr", "--recursive", "--ignore-all-space", "--ignore-blank-lines", "--exclude=parameters.prop", "--exclude=.DS_Store", firstDir, secondDir)
+// ChangeType describes how a file differs between the first and second location of a comparison
+type ChangeType string
 
-	stdoutStderr, err := cmd.CombinedOutput()
-	// An error means there is a difference
-	if err != nil {
-		log.Info().Msgf("Diff results:\n%v", string(stdoutStderr))
-	}
+const (
+	Added   ChangeType = "added"   // File only exists in the second location
+	Removed ChangeType = "removed" // File only exists in the first location
+	Changed ChangeType = "changed" // File exists in both locations with different content
+)
+
+// DiffOptions control which content is ignored during a comparison. Trailing carriage returns, white space and
+// blank lines are always ignored.
+type DiffOptions struct {
+	IgnoreLines *regexp.Regexp // Lines matching this pattern are ignored
+	Exclude     []string       // Files and directories with base names matching these glob patterns are skipped
+	Context     int            // Number of unchanged lines around each change included in a hunk
+	SemanticXML bool           // Compare the canonical XML form of files where IsSemanticXMLFile is true
+}
 
-	return err != nil
+// Hunk is a block of changed lines in unified diff format
+type Hunk struct {
+	FirstStart  int
+	FirstLines  int
+	SecondStart int
+	SecondLines int
+	Lines       []string // Lines prefixed with " ", "-" or "+"
 }
 
-func DiffFile(firstFile string, secondFile string) bool {
+// FileDiff is a file that differs between the first and second location of a comparison
+type FileDiff struct {
+	Path   string // Path relative to the compared directories
+	Change ChangeType
+	Binary bool
+	Hunks  []Hunk
+}
+
+// DiffResult is the result of comparing two directories or files
+type DiffResult struct {
+	FirstPath  string
+	SecondPath string
+	Files      []FileDiff
+}
+
+var (
+	originLines  = regexp.MustCompile(`^Origin.*`)
+	commentLines = regexp.MustCompile(`^#.*`)
+)
+
+// DirectoryDiffOptions are the options used by DiffDirectories
+var DirectoryDiffOptions = DiffOptions{
+	IgnoreLines: originLines,
+	Exclude:     []string{"parameters.prop", ".DS_Store"},
+	Context:     3,
+	SemanticXML: true,
+}
+
+// FileDiffOptions are the options used by DiffFile
+var FileDiffOptions = DiffOptions{
+	IgnoreLines: commentLines,
+	Context:     3,
+	SemanticXML: true,
+}
+
+// maxEditDistance limits the effort spent on finding the minimal set of changes. Files that differ by more lines are
+// reported as a complete replacement.
+const maxEditDistance = 2000
+
+// DiffDirectories compares the directories with DirectoryDiffOptions and logs the differences. Directories that cannot
+// be compared are reported as changed.
+func DiffDirectories(firstDir string, secondDir string) *DiffResult {
+	log.Info().Msgf("Comparing directories %v and %v (ignoring lines matching %v, trailing CR, white space, blank lines, %v)", firstDir, secondDir, DirectoryDiffOptions.IgnoreLines, strings.Join(DirectoryDiffOptions.Exclude, ", "))
+	result, err := CompareDirectories(firstDir, secondDir, DirectoryDiffOptions)
+	return logDiffResult(firstDir, secondDir, result, err)
+}
+
+// DiffFile compares the files with FileDiffOptions and logs the differences. Files that cannot be compared are
+// reported as changed.
+func DiffFile(firstFile string, secondFile string) *DiffResult {
 	// - ignoring commented lines (beginning with #)
 	// - ignoring blank lines and extra white space
-	log.Info().Msgf("Executing command: diff --ignore-matching-lines=^#.* --strip-trailing-cr --ignore-all-space --ignore-blank-lines %v %v", firstFile, secondFile)
-	cmd := exec.Command("diff", "--ignore-matching-lines=^#.*", "--strip-trailing-cr", "--ignore-all-space", "--ignore-blank-lines", firstFile, secondFile)
+	log.Info().Msgf("Comparing files %v and %v (ignoring lines matching %v, trailing CR, white space, blank lines)", firstFile, secondFile, FileDiffOptions.IgnoreLines)
+	result, err := CompareFiles(firstFile, secondFile, FileDiffOptions)
+	return logDiffResult(firstFile, secondFile, result, err)
+}
 
-	stdoutStderr, err := cmd.CombinedOutput()
+func logDiffResult(firstPath string, secondPath string, result *DiffResult, err error) *DiffResult {
 	// An error means there is a difference
 	if err != nil {
-		log.Info().Msgf("Diff results:\n%v", string(stdoutStderr))
+		log.Info().Msgf("Diff results:\n%v", err)
+		return &DiffResult{FirstPath: firstPath, SecondPath: secondPath, Files: []FileDiff{{Change: Changed}}}
+	}
+	if result.HasDifferences() {
+		log.Info().Msgf("Diff results:\n%v", result)
+	}
+	return result
+}
+
+// HasDifferences returns true if at least one file differs
+func (r *DiffResult) HasDifferences() bool {
+	return r != nil && len(r.Files) > 0
+}
+
+// Merge adds the differences of the other result, with their paths relative to the first path of this result
+func (r *DiffResult) Merge(other *DiffResult) {
+	if other == nil {
+		return
+	}
+	for _, f := range other.Files {
+		path := filepath.Join(other.FirstPath, f.Path)
+		if rel, err := filepath.Rel(r.FirstPath, path); err == nil {
+			path = rel
+		}
+		f.Path = path
+		r.Files = append(r.Files, f)
+	}
+}
+
+// Summary returns a line for each file that differs, with the type of change and the number of changed blocks
+func (r *DiffResult) Summary() string {
+	if r == nil {
+		return ""
+	}
+	var lines []string
+	for _, f := range r.Files {
+		path := filepath.ToSlash(f.Path)
+		if path == "" {
+			path = filepath.Base(r.FirstPath)
+		}
+		switch {
+		case f.Change != Changed:
+			lines = append(lines, fmt.Sprintf("%v: %v", path, f.Change))
+		case f.Binary:
+			lines = append(lines, fmt.Sprintf("%v: %v (binary)", path, f.Change))
+		case len(f.Hunks) == 1:
+			lines = append(lines, fmt.Sprintf("%v: %v (1 hunk)", path, f.Change))
+		case len(f.Hunks) > 1:
+			lines = append(lines, fmt.Sprintf("%v: %v (%d hunks)", path, f.Change, len(f.Hunks)))
+		default:
+			lines = append(lines, fmt.Sprintf("%v: %v", path, f.Change))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String returns the differences in unified diff format
+func (r *DiffResult) String() string {
+	var b strings.Builder
+	for _, f := range r.Files {
+		first := filepath.Join(r.FirstPath, f.Path)
+		second := filepath.Join(r.SecondPath, f.Path)
+		switch {
+		case f.Change == Removed:
+			fmt.Fprintf(&b, "Only in %v: %v\n", filepath.Dir(first), filepath.Base(first))
+		case f.Change == Added:
+			fmt.Fprintf(&b, "Only in %v: %v\n", filepath.Dir(second), filepath.Base(second))
+		case f.Binary || len(f.Hunks) == 0:
+			fmt.Fprintf(&b, "Files %v and %v differ\n", first, second)
+		default:
+			fmt.Fprintf(&b, "--- %v\n+++ %v\n", first, second)
+			for _, h := range f.Hunks {
+				fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h.FirstStart, h.FirstLines, h.SecondStart, h.SecondLines)
+				for _, line := range h.Lines {
+					b.WriteString(line)
+					b.WriteString("\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// CompareDirectories recursively compares the files of two directories
+func CompareDirectories(firstDir string, secondDir string, opts DiffOptions) (*DiffResult, error) {
+	result := &DiffResult{FirstPath: firstDir, SecondPath: secondDir}
+	for _, dir := range []string{firstDir, secondDir} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%v is not a directory", dir)
+		}
+	}
+	err := compareDirectory(firstDir, secondDir, "", opts, result)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	return err != nil
+func compareDirectory(firstDir string, secondDir string, relativePath string, opts DiffOptions, result *DiffResult) error {
+	firstEntries, err := readEntries(filepath.Join(firstDir, relativePath), opts.Exclude)
+	if err != nil {
+		return err
+	}
+	secondEntries, err := readEntries(filepath.Join(secondDir, relativePath), opts.Exclude)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range firstEntries {
+		names = append(names, name)
+	}
+	for name := range secondEntries {
+		if _, found := firstEntries[name]; !found {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		path := filepath.Join(relativePath, name)
+		first, inFirst := firstEntries[name]
+		second, inSecond := secondEntries[name]
+		switch {
+		case !inSecond:
+			result.Files = append(result.Files, FileDiff{Path: path, Change: Removed})
+		case !inFirst:
+			result.Files = append(result.Files, FileDiff{Path: path, Change: Added})
+		case first.IsDir() && second.IsDir():
+			err = compareDirectory(firstDir, secondDir, path, opts, result)
+			if err != nil {
+				return err
+			}
+		case first.IsDir() != second.IsDir():
+			result.Files = append(result.Files, FileDiff{Path: path, Change: Changed})
+		default:
+			fileDiff, err := compareFile(filepath.Join(firstDir, path), filepath.Join(secondDir, path), opts)
+			if err != nil {
+				return err
+			}
+			if fileDiff != nil {
+				fileDiff.Path = path
+				result.Files = append(result.Files, *fileDiff)
+			}
+		}
+	}
+	return nil
+}
+
+func readEntries(dir string, exclude []string) (map[string]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	result := map[string]os.DirEntry{}
+	for _, entry := range entries {
+		if !isExcluded(entry.Name(), exclude) {
+			result[entry.Name()] = entry
+		}
+	}
+	return result, nil
+}
+
+func isExcluded(name string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareFiles compares the content of two files
+func CompareFiles(firstFile string, secondFile string, opts DiffOptions) (*DiffResult, error) {
+	result := &DiffResult{FirstPath: firstFile, SecondPath: secondFile}
+	fileDiff, err := compareFile(firstFile, secondFile, opts)
+	if err != nil {
+		return nil, err
+	}
+	if fileDiff != nil {
+		result.Files = append(result.Files, *fileDiff)
+	}
+	return result, nil
+}
+
+// compareFile returns nil if the content of both files is the same
+func compareFile(firstFile string, secondFile string, opts DiffOptions) (*FileDiff, error) {
+	firstContent, err := os.ReadFile(firstFile)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	secondContent, err := os.ReadFile(secondFile)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+
+	if isBinary(firstContent) || isBinary(secondContent) {
+		if bytes.Equal(firstContent, secondContent) {
+			return nil, nil
+		}
+		return &FileDiff{Change: Changed, Binary: true}, nil
+	}
+
+	ignoreLines := opts.IgnoreLines
+	if opts.SemanticXML && IsSemanticXMLFile(firstFile) {
+		firstCanonical, firstErr := CanonicalXML(firstContent)
+		secondCanonical, secondErr := CanonicalXML(secondContent)
+		// Fall back to comparing line by line if either file is not well-formed XML
+		if firstErr == nil && secondErr == nil {
+			firstContent = []byte(firstCanonical)
+			secondContent = []byte(secondCanonical)
+			ignoreLines = nil
+		} else {
+			log.Warn().Msgf("Unable to compare XML content of %v and %v, comparing line by line instead", firstFile, secondFile)
+		}
+	}
+
+	firstLines := splitLines(firstContent, ignoreLines)
+	secondLines := splitLines(secondContent, ignoreLines)
+	edits := diffLines(firstLines, secondLines)
+	if !slices.ContainsFunc(edits, func(e edit) bool { return e.op != ' ' }) {
+		return nil, nil
+	}
+	return &FileDiff{Change: Changed, Hunks: buildHunks(edits, firstLines, secondLines, opts.Context)}, nil
+}
+
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) != -1
+}
+
+type line struct {
+	number int    // Line number in the original file
+	text   string // Original text without line ending
+	key    string // Text used for comparison with all white space removed
+}
+
+func splitLines(content []byte, ignore *regexp.Regexp) []line {
+	var lines []line
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+	for i, l := range strings.Split(text, "\n") {
+		l = strings.TrimSuffix(l, "\r")
+		key := strings.Join(strings.Fields(l), "")
+		if key == "" || (ignore != nil && ignore.MatchString(l)) {
+			continue
+		}
+		lines = append(lines, line{number: i + 1, text: l, key: key})
+	}
+	return lines
+}
+
+type edit struct {
+	op     byte // ' ' for unchanged, '-' for removed, '+' for added
+	first  int  // Index in first lines (unchanged and removed)
+	second int  // Index in second lines (unchanged and added)
+	text   string
+}
+
+// diffLines computes the shortest edit script between the lines using the Myers algorithm
+func diffLines(a []line, b []line) []edit {
+	n, m := len(a), len(b)
+	// Common prefix and suffix are unchanged and do not need to go through the algorithm
+	prefix := 0
+	for prefix < n && prefix < m && a[prefix].key == b[prefix].key {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && a[n-1-suffix].key == b[m-1-suffix].key {
+		suffix++
+	}
+
+	var edits []edit
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{op: ' ', first: i, second: i, text: a[i].text})
+	}
+	edits = append(edits, myers(a[prefix:n-suffix], b[prefix:m-suffix], prefix, prefix)...)
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, edit{op: ' ', first: n - i, second: m - i, text: a[n-i].text})
+	}
+	return edits
+}
+
+func myers(a []line, b []line, firstOffset int, secondOffset int) []edit {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxEditDistance)
+	v := make([]int, 2*maxD+3)
+	offset := maxD + 1
+	// The trace only keeps the diagonals -d-1 to d+1 of each step, which are the ones read when backtracking
+	var trace [][]int
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x].key == b[y].key {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	var edits []edit
+	if !found {
+		// Too many differences, report as complete replacement
+		for i := range a {
+			edits = append(edits, edit{op: '-', first: firstOffset + i, second: secondOffset, text: a[i].text})
+		}
+		for i := range b {
+			edits = append(edits, edit{op: '+', first: firstOffset + n, second: secondOffset + i, text: b[i].text})
+		}
+		return edits
+	}
+
+	// Backtrack through the trace to build the edit script in reverse
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		tv := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && tv[d+k] < tv[d+k+2]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := tv[d+1+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{op: ' ', first: firstOffset + x, second: secondOffset + y, text: a[x].text})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{op: '+', first: firstOffset + x, second: secondOffset + prevY, text: b[prevY].text})
+			} else {
+				edits = append(edits, edit{op: '-', first: firstOffset + prevX, second: secondOffset + y, text: a[prevX].text})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(edits)
+	return edits
+}
+
+func buildHunks(edits []edit, firstLines []line, secondLines []line, context int) []Hunk {
+	var hunks []Hunk
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk until there are more than 2 * context unchanged lines between changes
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != ' ' {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		end = min(end+context, len(edits)-1)
+
+		h := Hunk{FirstStart: lineNumber(firstLines, edits[start].first), SecondStart: lineNumber(secondLines, edits[start].second)}
+		for _, e := range edits[start : end+1] {
+			h.Lines = append(h.Lines, string(e.op)+e.text)
+			if e.op != '+' {
+				h.FirstLines++
+			}
+			if e.op != '-' {
+				h.SecondLines++
+			}
+		}
+		hunks = append(hunks, h)
+		i = end + 1
+	}
+	return hunks
+}
+
+// lineNumber returns the line number in the original file, as ignored lines are not part of the comparison
+func lineNumber(lines []line, index int) int {
+	if index < len(lines) {
+		return lines[index].number
+	}
+	if len(lines) > 0 {
+		return lines[len(lines)-1].number + 1
+	}
+	return 1
 }
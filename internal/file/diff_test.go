@@ -1,24 +1,143 @@
 package file
 
 import (
-	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDiffDirectories_SameIgnoringOrigin(t *testing.T) {
 	contentDiffer := DiffDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/Dir2/")
 
-	assert.False(t, contentDiffer, "Directory contents differ")
+	assert.False(t, contentDiffer.HasDifferences(), "Directory contents differ")
 }
 
 func TestDiffDirectories_Different(t *testing.T) {
 	contentDiffer := DiffDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/Dir3/")
 
-	assert.True(t, contentDiffer, "Directory contents do not differ")
+	assert.True(t, contentDiffer.HasDifferences(), "Directory contents do not differ")
 }
 
 func TestDiffFile_Different(t *testing.T) {
 	fileDiffer := DiffFile("../../test/testdata/DiffComparison/Dir1/MANIFEST.MF", "../../test/testdata/DiffComparison/Dir3/MANIFEST.MF")
 
-	assert.True(t, fileDiffer, "File contents do not differ")
+	assert.True(t, fileDiffer.HasDifferences(), "File contents do not differ")
+}
+
+func TestDiffDirectories_MissingDirectory(t *testing.T) {
+	contentDiffer := DiffDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/DirX/")
+
+	assert.True(t, contentDiffer.HasDifferences(), "Missing directory is not reported as difference")
+}
+
+func TestDiffResult_Merge(t *testing.T) {
+	result := &DiffResult{FirstPath: "first", SecondPath: "second"}
+	result.Merge(&DiffResult{FirstPath: "first/META-INF", SecondPath: "second/META-INF", Files: []FileDiff{{Path: "MANIFEST.MF", Change: Changed}}})
+	result.Merge(&DiffResult{FirstPath: "first/metainfo.prop", SecondPath: "second/metainfo.prop", Files: []FileDiff{{Change: Changed}}})
+
+	assert.Equal(t, 2, len(result.Files))
+	assert.Equal(t, filepath.Join("META-INF", "MANIFEST.MF"), result.Files[0].Path)
+	assert.Equal(t, "metainfo.prop", result.Files[1].Path)
+}
+
+func TestCompareDirectories_ChangedHunk(t *testing.T) {
+	result, err := CompareDirectories("../../test/testdata/DiffComparison/Dir1/", "../../test/testdata/DiffComparison/Dir3/", DirectoryDiffOptions)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Files), "Expected one file to differ")
+	assert.Equal(t, "MANIFEST.MF", result.Files[0].Path)
+	assert.Equal(t, Changed, result.Files[0].Change)
+	assert.Equal(t, 1, len(result.Files[0].Hunks), "Expected one hunk")
+	assert.Contains(t, result.Files[0].Hunks[0].Lines, "-Bundle-Version: 1.0.0")
+	assert.Contains(t, result.Files[0].Hunks[0].Lines, "+Bundle-Version: 1.0.1")
+}
+
+func TestCompareDirectories_AddedRemovedExcluded(t *testing.T) {
+	firstDir := t.TempDir()
+	secondDir := t.TempDir()
+	writeFile(t, firstDir+"/common.txt", "A\r\nB  C\n\nOrigin: first\n")
+	writeFile(t, secondDir+"/common.txt", "A\nB C\nOrigin: second\n")
+	writeFile(t, firstDir+"/removed.txt", "removed")
+	writeFile(t, secondDir+"/sub/added.txt", "added")
+	writeFile(t, firstDir+"/parameters.prop", "a=1")
+	writeFile(t, secondDir+"/.DS_Store", "ignored")
+
+	result, err := CompareDirectories(firstDir, secondDir, DirectoryDiffOptions)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []FileDiff{
+		{Path: "removed.txt", Change: Removed},
+		{Path: "sub", Change: Added},
+	}, result.Files)
+}
+
+func TestCompareFiles_IgnoreComments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir+"/first.prop", "# Generated on Monday\na=1\nb=2\n")
+	writeFile(t, dir+"/second.prop", "# Generated on Tuesday\na=1\n\nb = 2\n")
+
+	result, err := CompareFiles(dir+"/first.prop", dir+"/second.prop", FileDiffOptions)
+
+	assert.NoError(t, err)
+	assert.False(t, result.HasDifferences(), "File contents differ")
+}
+
+func TestCompareFiles_Hunks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir+"/first.txt", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n")
+	writeFile(t, dir+"/second.txt", "1\n2\nx\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n15\n16\n")
+
+	result, err := CompareFiles(dir+"/first.txt", dir+"/second.txt", FileDiffOptions)
+
+	assert.NoError(t, err)
+	hunks := result.Files[0].Hunks
+	assert.Equal(t, 2, len(hunks), "Expected two hunks")
+	assert.Equal(t, Hunk{FirstStart: 1, FirstLines: 5, SecondStart: 1, SecondLines: 6, Lines: []string{" 1", " 2", "+x", " 3", " 4", " 5"}}, hunks[0])
+	assert.Equal(t, Hunk{FirstStart: 11, FirstLines: 6, SecondStart: 12, SecondLines: 5, Lines: []string{" 11", " 12", " 13", "-14", " 15", " 16"}}, hunks[1])
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestDiffResult_Summary(t *testing.T) {
+	result := &DiffResult{FirstPath: "first", SecondPath: "second", Files: []FileDiff{
+		{Path: filepath.Join("META-INF", "MANIFEST.MF"), Change: Changed, Hunks: []Hunk{{}, {}}},
+		{Path: "metainfo.prop", Change: Added},
+		{Path: "lib.jar", Change: Changed, Binary: true},
+	}}
+
+	assert.Equal(t, "META-INF/MANIFEST.MF: changed (2 hunks)\nmetainfo.prop: added\nlib.jar: changed (binary)", result.Summary())
+}
+
+func TestDiffLines_EditScript(t *testing.T) {
+	toLines := func(texts ...string) []line {
+		var lines []line
+		for i, text := range texts {
+			lines = append(lines, line{number: i + 1, text: text, key: text})
+		}
+		return lines
+	}
+	first := toLines("a", "b", "c", "a", "b", "b", "a")
+	second := toLines("c", "b", "a", "b", "a", "c")
+
+	var gotFirst, gotSecond []string
+	changes := 0
+	for _, e := range diffLines(first, second) {
+		if e.op != '+' {
+			gotFirst = append(gotFirst, e.text)
+		}
+		if e.op != '-' {
+			gotSecond = append(gotSecond, e.text)
+		}
+		if e.op != ' ' {
+			changes++
+		}
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a", "b", "b", "a"}, gotFirst)
+	assert.Equal(t, []string{"c", "b", "a", "b", "a", "c"}, gotSecond)
+	assert.Equal(t, 5, changes, "Expected shortest edit script")
 }
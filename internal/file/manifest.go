@@ -0,0 +1,98 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-errors/errors"
+)
+
+// Max length of a line in MANIFEST.MF, longer values continue on the next line which starts with a space
+const manifestLineWidth = 72
+
+// GetManifestHeader returns the value of the header in MANIFEST.MF, with its continuation lines joined.
+func GetManifestHeader(manifestPath string, name string) (string, error) {
+	lines, _, err := readManifestLines(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], name+":") {
+			continue
+		}
+		value := strings.TrimPrefix(lines[i], name+":")
+		for i+1 < len(lines) && strings.HasPrefix(lines[i+1], " ") {
+			i++
+			value += lines[i][1:]
+		}
+		return strings.TrimSpace(value), nil
+	}
+	return "", nil
+}
+
+// SetManifestHeader replaces the value of the header in MANIFEST.MF. The header is added if it does not exist yet.
+func SetManifestHeader(manifestPath string, name string, value string) error {
+	lines, newline, err := readManifestLines(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	var output []string
+	found := false
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], name+":") {
+			output = append(output, lines[i])
+			continue
+		}
+		// Skip the continuation lines of the existing value
+		for i+1 < len(lines) && strings.HasPrefix(lines[i+1], " ") {
+			i++
+		}
+		output = append(output, wrapManifestLine(fmt.Sprintf("%v: %v", name, value))...)
+		found = true
+	}
+	if !found {
+		// Headers end at the first empty line
+		end := len(output)
+		for end > 0 && output[end-1] == "" {
+			end--
+		}
+		header := wrapManifestLine(fmt.Sprintf("%v: %v", name, value))
+		output = append(output[:end], append(header, output[end:]...)...)
+	}
+
+	err = os.WriteFile(manifestPath, []byte(strings.Join(output, newline)), 0644)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
+
+// readManifestLines returns the lines of MANIFEST.MF and the line separator used in it
+func readManifestLines(manifestPath string) ([]string, string, error) {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, "", errors.Wrap(err, 0)
+	}
+	newline := "\n"
+	if strings.Contains(string(content), "\r\n") {
+		newline = "\r\n"
+	}
+	return strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"), newline, nil
+}
+
+func wrapManifestLine(line string) []string {
+	lines := []string{}
+	for len(line) > manifestLineWidth {
+		// Do not split multibyte characters
+		end := manifestLineWidth
+		for end > 1 && !utf8.RuneStart(line[end]) {
+			end--
+		}
+		lines = append(lines, line[:end])
+		line = " " + line[end:]
+	}
+	return append(lines, line)
+}
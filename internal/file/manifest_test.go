@@ -0,0 +1,75 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifest = "Manifest-Version: 1.0\r\n" +
+	"Bundle-SymbolicName: Integration_Flow_With_A_Very_Long_Identifier_That_Wra\r\n" +
+	" ps; singleton:=true\r\n" +
+	"Bundle-Name: Short name\r\n" +
+	"Bundle-Version: 1.0.0\r\n" +
+	"\r\n"
+
+func writeTestManifest(t *testing.T) string {
+	manifestPath := filepath.Join(t.TempDir(), "MANIFEST.MF")
+	err := os.WriteFile(manifestPath, []byte(testManifest), 0644)
+	assert.NoError(t, err)
+	return manifestPath
+}
+
+func TestGetManifestHeader_JoinsContinuationLines(t *testing.T) {
+	manifestPath := writeTestManifest(t)
+
+	value, err := GetManifestHeader(manifestPath, "Bundle-SymbolicName")
+	assert.NoError(t, err)
+	assert.Equal(t, "Integration_Flow_With_A_Very_Long_Identifier_That_Wraps; singleton:=true", value)
+
+	value, err = GetManifestHeader(manifestPath, "Bundle-Description")
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+}
+
+func TestSetManifestHeader_WrapsLongValue(t *testing.T) {
+	manifestPath := writeTestManifest(t)
+	name := "Transported integration flow with a name that is longer than the width of a manifest line"
+
+	err := SetManifestHeader(manifestPath, "Bundle-Name", name)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(manifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "Manifest-Version: 1.0\r\n"+
+		"Bundle-SymbolicName: Integration_Flow_With_A_Very_Long_Identifier_That_Wra\r\n"+
+		" ps; singleton:=true\r\n"+
+		"Bundle-Name: Transported integration flow with a name that is longer tha\r\n"+
+		" n the width of a manifest line\r\n"+
+		"Bundle-Version: 1.0.0\r\n"+
+		"\r\n", string(content))
+
+	value, err := GetManifestHeader(manifestPath, "Bundle-Name")
+	assert.NoError(t, err)
+	assert.Equal(t, name, value)
+}
+
+func TestSetManifestHeader_ReplacesContinuationLines(t *testing.T) {
+	manifestPath := writeTestManifest(t)
+
+	err := SetManifestHeader(manifestPath, "Bundle-SymbolicName", "QA_IFlow;singleton:=true")
+	assert.NoError(t, err)
+	err = SetManifestHeader(manifestPath, "Bundle-Description", "Added")
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(manifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "Manifest-Version: 1.0\r\n"+
+		"Bundle-SymbolicName: QA_IFlow;singleton:=true\r\n"+
+		"Bundle-Name: Short name\r\n"+
+		"Bundle-Version: 1.0.0\r\n"+
+		"Bundle-Description: Added\r\n"+
+		"\r\n", string(content))
+}
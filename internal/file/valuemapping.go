@@ -0,0 +1,269 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the value mapping content in an artifact directory
+const (
+	ValueMappingXML  = "xml"
+	ValueMappingCSV  = "csv"
+	ValueMappingYAML = "yaml"
+)
+
+// ValueMappingFormats lists the supported formats, in the order they are looked up in an artifact directory
+var ValueMappingFormats = []string{ValueMappingXML, ValueMappingCSV, ValueMappingYAML}
+
+// ValueMappingEntry is the value of an agency and identifier.
+type ValueMappingEntry struct {
+	Agency     string `yaml:"agency"`
+	Identifier string `yaml:"identifier"`
+	Value      string `yaml:"value"`
+}
+
+// ValueMappingGroup contains the entries that are mapped to each other.
+type ValueMappingGroup struct {
+	Entries []ValueMappingEntry `yaml:"entries"`
+}
+
+type valueMappingXML struct {
+	XMLName xml.Name `xml:"vm"`
+	Version string   `xml:"version,attr"`
+	Groups  []struct {
+		Id      string `xml:"id,attr"`
+		Entries []struct {
+			Agency string `xml:"agency"`
+			Schema string `xml:"schema"`
+			Value  string `xml:"value"`
+		} `xml:"entry"`
+	} `xml:"group"`
+}
+
+// ValueMappingFile returns the path of the value mapping content in the format.
+func ValueMappingFile(artifactDir string, format string) string {
+	return fmt.Sprintf("%v/value_mapping.%v", artifactDir, format)
+}
+
+// ValueMappingFormat returns the format of the value mapping content in the artifact directory, or an empty string if
+// there is none.
+func ValueMappingFormat(artifactDir string) string {
+	for _, format := range ValueMappingFormats {
+		if Exists(ValueMappingFile(artifactDir, format)) {
+			return format
+		}
+	}
+	return ""
+}
+
+// ReadValueMappings returns the groups of the value mapping content in the artifact directory, in any of the formats.
+func ReadValueMappings(artifactDir string) ([]ValueMappingGroup, error) {
+	format := ValueMappingFormat(artifactDir)
+	if format == "" {
+		return nil, fmt.Errorf("value mapping content value_mapping.xml, value_mapping.csv or value_mapping.yaml not found in %v", artifactDir)
+	}
+	filePath := ValueMappingFile(artifactDir, format)
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	var groups []ValueMappingGroup
+	switch format {
+	case ValueMappingCSV:
+		groups, err = parseValueMappingCSV(content)
+	case ValueMappingYAML:
+		err = yaml.Unmarshal(content, &groups)
+	default:
+		groups, err = parseValueMappingXML(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value mapping content in %v: %w", filePath, err)
+	}
+	return groups, nil
+}
+
+// WriteValueMappings writes the groups to the artifact directory in the format, and removes the content in the other
+// formats.
+func WriteValueMappings(artifactDir string, groups []ValueMappingGroup, format string) error {
+	var content []byte
+	var err error
+	switch format {
+	case ValueMappingCSV:
+		content, err = formatValueMappingCSV(groups)
+	case ValueMappingYAML:
+		content, err = yaml.Marshal(groups)
+	case ValueMappingXML:
+		content, err = formatValueMappingXML(groups)
+	default:
+		return fmt.Errorf("invalid value mapping format %v", format)
+	}
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	for _, f := range ValueMappingFormats {
+		if f != format {
+			err = os.RemoveAll(ValueMappingFile(artifactDir, f))
+			if err != nil {
+				return errors.Wrap(err, 0)
+			}
+		}
+	}
+	err = os.WriteFile(ValueMappingFile(artifactDir, format), content, 0644)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
+
+// ConvertValueMappings converts the value mapping content in the artifact directory to the format.
+func ConvertValueMappings(artifactDir string, format string) error {
+	if ValueMappingFormat(artifactDir) == format {
+		return nil
+	}
+	groups, err := ReadValueMappings(artifactDir)
+	if err != nil {
+		return err
+	}
+	return WriteValueMappings(artifactDir, groups, format)
+}
+
+// EqualValueMappings returns true if both contain the same groups with the same entries, regardless of their order.
+func EqualValueMappings(first []ValueMappingGroup, second []ValueMappingGroup) bool {
+	return slices.Equal(normaliseValueMappings(first), normaliseValueMappings(second))
+}
+
+func normaliseValueMappings(groups []ValueMappingGroup) []string {
+	var normalised []string
+	for _, group := range groups {
+		normalised = append(normalised, groupKey(group))
+	}
+	slices.Sort(normalised)
+	return normalised
+}
+
+// groupKey returns a representation of the group that does not depend on the order of its entries
+func groupKey(group ValueMappingGroup) string {
+	var entries []string
+	for _, e := range group.Entries {
+		entries = append(entries, fmt.Sprintf("%q|%q|%q", e.Agency, e.Identifier, e.Value))
+	}
+	slices.Sort(entries)
+	return strings.Join(entries, "\n")
+}
+
+func parseValueMappingXML(content []byte) ([]ValueMappingGroup, error) {
+	var vm valueMappingXML
+	err := xml.Unmarshal(content, &vm)
+	if err != nil {
+		return nil, err
+	}
+	var groups []ValueMappingGroup
+	for _, g := range vm.Groups {
+		var group ValueMappingGroup
+		for _, e := range g.Entries {
+			group.Entries = append(group.Entries, ValueMappingEntry{Agency: e.Agency, Identifier: e.Schema, Value: e.Value})
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func formatValueMappingXML(groups []ValueMappingGroup) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<vm version=\"2.0\">\n")
+	for _, group := range groups {
+		// Group IDs are derived from the entries so that the same content always results in the same XML
+		hash := sha1.Sum([]byte(groupKey(group)))
+		fmt.Fprintf(&buf, "    <group id=\"%v\">\n", hex.EncodeToString(hash[:16]))
+		for _, e := range group.Entries {
+			buf.WriteString("        <entry>\n")
+			for _, element := range [][2]string{{"agency", e.Agency}, {"schema", e.Identifier}, {"value", e.Value}} {
+				fmt.Fprintf(&buf, "            <%v>", element[0])
+				err := xml.EscapeText(&buf, []byte(element[1]))
+				if err != nil {
+					return nil, err
+				}
+				fmt.Fprintf(&buf, "</%v>\n", element[0])
+			}
+			buf.WriteString("        </entry>\n")
+		}
+		buf.WriteString("    </group>\n")
+	}
+	buf.WriteString("</vm>\n")
+	return buf.Bytes(), nil
+}
+
+// The CSV format has the agencies in the first row and the identifiers in the second row. Each following row is a
+// group, with the value of each agency and identifier in its column. An empty cell means the group has no entry for the
+// agency and identifier.
+func parseValueMappingCSV(content []byte) ([]ValueMappingGroup, error) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("expected a row of agencies followed by a row of identifiers")
+	}
+	agencies, identifiers := records[0], records[1]
+	var groups []ValueMappingGroup
+	for _, record := range records[2:] {
+		var group ValueMappingGroup
+		for i, value := range record {
+			if value == "" {
+				continue
+			}
+			group.Entries = append(group.Entries, ValueMappingEntry{Agency: agencies[i], Identifier: identifiers[i], Value: value})
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func formatValueMappingCSV(groups []ValueMappingGroup) ([]byte, error) {
+	// Columns are the agency and identifier pairs in the order they first appear
+	type column struct{ agency, identifier string }
+	var columns []column
+	for _, group := range groups {
+		for _, e := range group.Entries {
+			c := column{e.Agency, e.Identifier}
+			if !slices.Contains(columns, c) {
+				columns = append(columns, c)
+			}
+		}
+	}
+	agencies := make([]string, len(columns))
+	identifiers := make([]string, len(columns))
+	for i, c := range columns {
+		agencies[i], identifiers[i] = c.agency, c.identifier
+	}
+	records := [][]string{agencies, identifiers}
+	for _, group := range groups {
+		record := make([]string, len(columns))
+		for _, e := range group.Entries {
+			i := slices.Index(columns, column{e.Agency, e.Identifier})
+			if record[i] != "" {
+				return nil, fmt.Errorf("group has more than one value for agency %v and identifier %v", e.Agency, e.Identifier)
+			}
+			record[i] = e.Value
+		}
+		records = append(records, record)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err := w.WriteAll(records)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const valueMappingXMLContent = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<vm version="2.0">
+    <group id="6f0e5b0a1c2d4e8f9a0b1c2d3e4f5a6b">
+        <entry>
+            <agency>ERP</agency>
+            <schema>Plant</schema>
+            <value>1000</value>
+        </entry>
+        <entry>
+            <agency>B2B</agency>
+            <schema>PlantCode</schema>
+            <value>DE01</value>
+        </entry>
+    </group>
+    <group id="7a1f6c1b2d3e5f9a0b1c2d3e4f5a6b7c">
+        <entry>
+            <agency>ERP</agency>
+            <schema>Plant</schema>
+            <value>2000</value>
+        </entry>
+        <entry>
+            <agency>B2B</agency>
+            <schema>PlantCode</schema>
+            <value>US &amp; CA</value>
+        </entry>
+    </group>
+</vm>
+`
+
+func TestConvertValueMappings_CSV(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "value_mapping.xml"), valueMappingXMLContent)
+
+	err := ConvertValueMappings(dir, ValueMappingCSV)
+
+	assert.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "value_mapping.xml"))
+	content, _ := os.ReadFile(filepath.Join(dir, "value_mapping.csv"))
+	assert.Equal(t, "ERP,B2B\nPlant,PlantCode\n1000,DE01\n2000,US & CA\n", string(content))
+}
+
+func TestConvertValueMappings_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "value_mapping.xml"), valueMappingXMLContent)
+	original, err := ReadValueMappings(dir)
+	assert.NoError(t, err)
+
+	for _, format := range []string{ValueMappingYAML, ValueMappingCSV, ValueMappingXML} {
+		err = ConvertValueMappings(dir, format)
+		assert.NoError(t, err)
+		assert.Equal(t, format, ValueMappingFormat(dir))
+
+		groups, err := ReadValueMappings(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, original, groups, "Round trip through %v", format)
+	}
+}
+
+func TestReadValueMappings_CSVEmptyCell(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "value_mapping.csv"), "ERP,B2B,EDI\nPlant,PlantCode,GLN\n1000,DE01,\n")
+
+	groups, err := ReadValueMappings(dir)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ValueMappingGroup{{Entries: []ValueMappingEntry{
+		{Agency: "ERP", Identifier: "Plant", Value: "1000"},
+		{Agency: "B2B", Identifier: "PlantCode", Value: "DE01"},
+	}}}, groups)
+}
+
+func TestReadValueMappings_NotFound(t *testing.T) {
+	_, err := ReadValueMappings(t.TempDir())
+
+	assert.Error(t, err)
+}
+
+func TestEqualValueMappings_IgnoreOrder(t *testing.T) {
+	first := []ValueMappingGroup{
+		{Entries: []ValueMappingEntry{{"ERP", "Plant", "1000"}, {"B2B", "PlantCode", "DE01"}}},
+		{Entries: []ValueMappingEntry{{"ERP", "Plant", "2000"}, {"B2B", "PlantCode", "US01"}}},
+	}
+	reordered := []ValueMappingGroup{
+		{Entries: []ValueMappingEntry{{"B2B", "PlantCode", "US01"}, {"ERP", "Plant", "2000"}}},
+		{Entries: []ValueMappingEntry{{"ERP", "Plant", "1000"}, {"B2B", "PlantCode", "DE01"}}},
+	}
+	changed := []ValueMappingGroup{
+		{Entries: []ValueMappingEntry{{"ERP", "Plant", "1000"}, {"B2B", "PlantCode", "DE02"}}},
+		{Entries: []ValueMappingEntry{{"ERP", "Plant", "2000"}, {"B2B", "PlantCode", "US01"}}},
+	}
+
+	assert.True(t, EqualValueMappings(first, reordered))
+	assert.False(t, EqualValueMappings(first, changed))
+	assert.False(t, EqualValueMappings(first, first[:1]))
+}
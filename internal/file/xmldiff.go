@@ -0,0 +1,214 @@
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/beevik/etree"
+	"github.com/go-errors/errors"
+)
+
+// Namespaces of the BPMN diagram interchange elements which only contain the layout (shapes, coordinates) of a diagram
+var diagramNamespaces = []string{
+	"http://www.omg.org/spec/BPMN/20100524/DI",
+	"http://www.omg.org/spec/DD/20100524/DC",
+	"http://www.omg.org/spec/DD/20100524/DI",
+}
+
+// IsSemanticXMLFile returns true for files that are compared based on their XML content instead of line by line, i.e.
+// integration flow BPMN2 files, message mappings and value mappings
+func IsSemanticXMLFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".iflw", ".mmap":
+		return true
+	}
+	return filepath.Base(path) == "value_mapping.xml"
+}
+
+// Element paths (local names, from a parent to the element) of sibling elements where the order has no meaning, e.g.
+// the properties of a BPMN element and the groups of a value mapping. The order of all other elements is kept.
+var unorderedElements = []string{
+	"extensionElements/property",
+	"vm/group",
+	"vm/group/entry",
+}
+
+// CanonicalXML returns a canonical form of an XML document that ignores cosmetic differences:
+// - namespace prefixes (elements and attributes are qualified by the namespace URI)
+// - order of attributes, and of sibling elements in unorderedElements
+// - values of id attributes, which are numbered in order of appearance together with references to them
+// - white space around text, comments and processing instructions
+// - BPMN diagram layout elements
+func CanonicalXML(content []byte) (string, error) {
+	doc := etree.NewDocument()
+	err := doc.ReadFromBytes(content)
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	root := doc.Root()
+	if root == nil {
+		return "", errors.Wrap(fmt.Errorf("no root element found"), 0)
+	}
+	ids := map[string]string{}
+	collectIds(root, ids)
+	node := canonicalNode(root, nil, maskIds(ids))
+	count := 0
+	node.numberIds(ids, &count)
+	return strings.Join(node.lines(ids), "\n"), nil
+}
+
+// xmlNode is an element in canonical form
+type xmlNode struct {
+	name       string
+	attributes [][2]string // Qualified names and values, sorted by name
+	text       []string
+	children   []*xmlNode
+}
+
+// collectIds adds the values of the id attributes of the element and its descendants to ids
+func collectIds(e *etree.Element, ids map[string]string) {
+	if slices.Contains(diagramNamespaces, e.NamespaceURI()) {
+		return
+	}
+	if id := e.SelectAttrValue("id", ""); id != "" {
+		ids[id] = ""
+	}
+	for _, child := range e.ChildElements() {
+		collectIds(child, ids)
+	}
+}
+
+// canonicalNode returns the canonical form of the element. Unordered siblings are sorted by their lines with the ids
+// replaced by the placeholders of masked, as the ids are numbered after sorting.
+func canonicalNode(e *etree.Element, path []string, masked map[string]string) *xmlNode {
+	path = append(path, e.Tag)
+	n := &xmlNode{name: qualifiedName(e.NamespaceURI(), e.Tag)}
+	for _, a := range e.Attr {
+		if a.Space == "xmlns" || (a.Space == "" && a.Key == "xmlns") {
+			continue
+		}
+		n.attributes = append(n.attributes, [2]string{qualifiedName(a.NamespaceURI(), a.Key), a.Value})
+	}
+	slices.SortFunc(n.attributes, func(a, b [2]string) int {
+		return strings.Compare(a[0], b[0])
+	})
+
+	var unordered []int
+	for _, token := range e.Child {
+		switch t := token.(type) {
+		case *etree.Element:
+			if slices.Contains(diagramNamespaces, t.NamespaceURI()) {
+				continue
+			}
+			if isUnorderedElement(append(path, t.Tag)) {
+				unordered = append(unordered, len(n.children))
+			}
+			n.children = append(n.children, canonicalNode(t, path, masked))
+		case *etree.CharData:
+			if value := strings.TrimSpace(t.Data); value != "" {
+				n.text = append(n.text, value)
+			}
+		}
+	}
+
+	if len(unordered) < 2 {
+		return n
+	}
+	// Unordered siblings are sorted among their own positions
+	type sortItem struct {
+		node *xmlNode
+		key  string
+	}
+	sorted := make([]sortItem, len(unordered))
+	for i, index := range unordered {
+		sorted[i] = sortItem{n.children[index], strings.Join(n.children[index].lines(masked), "\n")}
+	}
+	slices.SortStableFunc(sorted, func(a, b sortItem) int {
+		return strings.Compare(a.key, b.key)
+	})
+	for i, index := range unordered {
+		n.children[index] = sorted[i].node
+	}
+	return n
+}
+
+func isUnorderedElement(path []string) bool {
+	joined := "/" + strings.Join(path, "/")
+	for _, element := range unorderedElements {
+		if strings.HasSuffix(joined, "/"+element) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskIds returns the ids with the same placeholder for all values
+func maskIds(ids map[string]string) map[string]string {
+	masked := map[string]string{}
+	for id := range ids {
+		masked[id] = "#"
+	}
+	return masked
+}
+
+// numberIds replaces the placeholders of the ids by their number in order of appearance
+func (n *xmlNode) numberIds(ids map[string]string, count *int) {
+	number := func(value string) {
+		if placeholder, found := ids[value]; found && placeholder == "" {
+			*count++
+			ids[value] = fmt.Sprintf("#%d", *count)
+		}
+	}
+	for _, a := range n.attributes {
+		number(a[1])
+	}
+	for _, t := range n.text {
+		number(t)
+	}
+	for _, child := range n.children {
+		child.numberIds(ids, count)
+	}
+}
+
+// lines returns the lines of the canonical form, with id values and references replaced by their placeholder in ids
+func (n *xmlNode) lines(ids map[string]string) []string {
+	replace := func(value string) string {
+		if placeholder, found := ids[value]; found && placeholder != "" {
+			return placeholder
+		}
+		return value
+	}
+	start := "<" + n.name
+	for _, a := range n.attributes {
+		start += fmt.Sprintf(" %v=%q", a[0], replace(a[1]))
+	}
+	var text []string
+	for _, t := range n.text {
+		text = append(text, replace(t))
+	}
+	if len(n.children) == 0 && len(text) == 0 {
+		return []string{start + "/>"}
+	}
+	if len(n.children) == 0 {
+		return []string{start + ">" + strings.Join(text, " ") + "</" + n.name + ">"}
+	}
+	lines := []string{start + ">"}
+	if len(text) > 0 {
+		lines = append(lines, "  "+strings.Join(text, " "))
+	}
+	for _, child := range n.children {
+		for _, l := range child.lines(ids) {
+			lines = append(lines, "  "+l)
+		}
+	}
+	return append(lines, "</"+n.name+">")
+}
+
+func qualifiedName(namespace string, local string) string {
+	if namespace == "" {
+		return local
+	}
+	return "{" + namespace + "}" + local
+}
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const firstIFlow = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:bpmndi="http://www.omg.org/spec/BPMN/20100524/DI" xmlns:dc="http://www.omg.org/spec/DD/20100524/DC" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd" id="Definitions_1">
+    <bpmn2:process id="Process_1" name="Integration Process">
+        <bpmn2:extensionElements>
+            <ifl:property><key>transactionTimeout</key><value>30</value></ifl:property>
+            <ifl:property><key>processType</key><value>directCall</value></ifl:property>
+        </bpmn2:extensionElements>
+        <bpmn2:startEvent id="StartEvent_2" name="Start"/>
+    </bpmn2:process>
+    <bpmndi:BPMNDiagram id="BPMNDiagram_1">
+        <bpmndi:BPMNShape bpmnElement="StartEvent_2" id="BPMNShape_StartEvent_2">
+            <dc:Bounds height="32.0" width="32.0" x="263.0" y="140.0"/>
+        </bpmndi:BPMNShape>
+    </bpmndi:BPMNDiagram>
+</bpmn2:definitions>`
+
+// Same content with different namespace prefixes, attribute and element order, and diagram coordinates
+const secondIFlow = `<?xml version="1.0" encoding="UTF-8"?><bpmn:definitions id="Definitions_1" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd" xmlns:bpmn="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:di="http://www.omg.org/spec/BPMN/20100524/DI" xmlns:dc="http://www.omg.org/spec/DD/20100524/DC">
+<bpmn:process name="Integration Process" id="Process_1">
+<bpmn:extensionElements>
+<ifl:property><key>processType</key><value>directCall</value></ifl:property>
+<ifl:property><key>transactionTimeout</key><value>30</value></ifl:property>
+</bpmn:extensionElements>
+<bpmn:startEvent name="Start" id="StartEvent_2"/>
+</bpmn:process>
+<di:BPMNDiagram id="BPMNDiagram_1"><di:BPMNShape bpmnElement="StartEvent_2" id="BPMNShape_StartEvent_2"><dc:Bounds height="32.0" width="32.0" x="300.0" y="100.0"/></di:BPMNShape></di:BPMNDiagram>
+</bpmn:definitions>`
+
+func TestCanonicalXML_IgnoreCosmeticDifferences(t *testing.T) {
+	first, err := CanonicalXML([]byte(firstIFlow))
+	assert.NoError(t, err)
+	second, err := CanonicalXML([]byte(secondIFlow))
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second, "Canonical XML differs")
+	assert.NotContains(t, first, "Bounds", "Diagram layout not removed")
+}
+
+func TestCompareFiles_SemanticXML(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir+"/first/IFlow.iflw", firstIFlow)
+	writeFile(t, dir+"/second/IFlow.iflw", secondIFlow)
+	writeFile(t, dir+"/changed/IFlow.iflw", strings.Replace(secondIFlow, "<value>30</value>", "<value>60</value>", 1))
+
+	result, err := CompareFiles(dir+"/first/IFlow.iflw", dir+"/second/IFlow.iflw", FileDiffOptions)
+	assert.NoError(t, err)
+	assert.False(t, result.HasDifferences(), "File contents differ")
+
+	result, err = CompareFiles(dir+"/first/IFlow.iflw", dir+"/changed/IFlow.iflw", FileDiffOptions)
+	assert.NoError(t, err)
+	assert.True(t, result.HasDifferences(), "File contents do not differ")
+	assert.Contains(t, result.String(), "+        <value>60</value>")
+}
+
+func TestIsSemanticXMLFile(t *testing.T) {
+	assert.True(t, IsSemanticXMLFile("src/main/resources/scenarioflows/integrationflow/IFlow1.iflw"))
+	assert.True(t, IsSemanticXMLFile("src/main/resources/mapping/Mapping1.mmap"))
+	assert.True(t, IsSemanticXMLFile("value_mapping.xml"))
+	assert.False(t, IsSemanticXMLFile("src/main/resources/xsd/Schema.xsd"))
+}
+
+func TestCanonicalXML_KeepOrderOfPositionalElements(t *testing.T) {
+	mapping := `<xiObj xmlns="urn:sap-com:xi"><key typeID="xsd"><elem>DT_Order.xsd</elem><elem>src/main/resources/xsd</elem></key>
+<brick type="Func"><arg><brick path="/ns0:Order/Id"/></arg><arg><brick path="/ns0:Order/Name"/></arg></brick></xiObj>`
+	swappedElem := strings.Replace(mapping, "<elem>DT_Order.xsd</elem><elem>src/main/resources/xsd</elem>", "<elem>src/main/resources/xsd</elem><elem>DT_Order.xsd</elem>", 1)
+	swappedArg := strings.Replace(mapping, `<arg><brick path="/ns0:Order/Id"/></arg><arg><brick path="/ns0:Order/Name"/></arg>`, `<arg><brick path="/ns0:Order/Name"/></arg><arg><brick path="/ns0:Order/Id"/></arg>`, 1)
+
+	original, err := CanonicalXML([]byte(mapping))
+	assert.NoError(t, err)
+	for _, changed := range []string{swappedElem, swappedArg} {
+		canonical, err := CanonicalXML([]byte(changed))
+		assert.NoError(t, err)
+		assert.NotEqual(t, original, canonical, "Change of element order not detected")
+	}
+}
+
+func TestCanonicalXML_NormaliseIds(t *testing.T) {
+	first := `<bpmn2:process xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" id="Process_1">
+<bpmn2:startEvent id="StartEvent_2"><bpmn2:outgoing>SequenceFlow_3</bpmn2:outgoing></bpmn2:startEvent>
+<bpmn2:endEvent id="EndEvent_4"><bpmn2:incoming>SequenceFlow_3</bpmn2:incoming></bpmn2:endEvent>
+<bpmn2:sequenceFlow id="SequenceFlow_3" sourceRef="StartEvent_2" targetRef="EndEvent_4"/>
+</bpmn2:process>`
+	renumbered := strings.NewReplacer("StartEvent_2", "StartEvent_7", "EndEvent_4", "EndEvent_8", "SequenceFlow_3", "SequenceFlow_9").Replace(first)
+	reversed := strings.Replace(first, `sourceRef="StartEvent_2" targetRef="EndEvent_4"`, `sourceRef="EndEvent_4" targetRef="StartEvent_2"`, 1)
+
+	canonical, err := CanonicalXML([]byte(first))
+	assert.NoError(t, err)
+	canonicalRenumbered, err := CanonicalXML([]byte(renumbered))
+	assert.NoError(t, err)
+	canonicalReversed, err := CanonicalXML([]byte(reversed))
+	assert.NoError(t, err)
+
+	assert.Equal(t, canonical, canonicalRenumbered, "Renumbered ids differ")
+	assert.NotEqual(t, canonical, canonicalReversed, "Change of references not detected")
+}
+
+func TestCanonicalXML_IgnoreOrderOfValueMappingGroups(t *testing.T) {
+	first := `<vm version="2.0"><group id="a1"><entry><agency>ERP</agency><value>1000</value></entry><entry><agency>B2B</agency><value>DE01</value></entry></group>
+<group id="b2"><entry><agency>ERP</agency><value>2000</value></entry><entry><agency>B2B</agency><value>US01</value></entry></group></vm>`
+	second := `<vm version="2.0"><group id="c3"><entry><agency>B2B</agency><value>US01</value></entry><entry><agency>ERP</agency><value>2000</value></entry></group>
+<group id="d4"><entry><agency>ERP</agency><value>1000</value></entry><entry><agency>B2B</agency><value>DE01</value></entry></group></vm>`
+
+	canonicalFirst, err := CanonicalXML([]byte(first))
+	assert.NoError(t, err)
+	canonicalSecond, err := CanonicalXML([]byte(second))
+	assert.NoError(t, err)
+
+	assert.Equal(t, canonicalFirst, canonicalSecond, "Value mapping groups differ")
+}
@@ -0,0 +1,103 @@
+package httpclnt
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors that can be matched against an *HTTPError using errors.Is
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+)
+
+// HTTPError is returned when an HTTP call does not complete with the expected response code.
+type HTTPError struct {
+	StatusCode int
+	CallType   string
+	Method     string
+	Path       string
+	Code       string // Error code from the OData error response body
+	Message    string // Error message from the OData error response body
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%v call failed with response code = %d", e.CallType, e.StatusCode)
+	if e.Message != "" {
+		msg = fmt.Sprintf("%v - %v", msg, e.Message)
+	}
+	return msg
+}
+
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+type odataJSONError struct {
+	Error struct {
+		Code    string          `json:"code"`
+		Message json.RawMessage `json:"message"`
+	} `json:"error"`
+}
+
+type odataXMLError struct {
+	XMLName xml.Name `xml:"error"`
+	Code    string   `xml:"code"`
+	Message string   `xml:"message"`
+}
+
+// newHTTPError constructs an HTTPError, extracting the OData error code and message from the response body if available.
+func newHTTPError(resp *http.Response, callType string, body []byte) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		CallType:   callType,
+		Body:       body,
+	}
+	if resp.Request != nil {
+		httpErr.Method = resp.Request.Method
+		httpErr.Path = resp.Request.URL.Path
+	}
+	httpErr.Code, httpErr.Message = parseODataError(body)
+	return httpErr
+}
+
+func parseODataError(body []byte) (code string, message string) {
+	var jsonErr odataJSONError
+	if err := json.Unmarshal(body, &jsonErr); err == nil {
+		code = jsonErr.Error.Code
+		// OData V2 returns message as an object with the text in value, while OData V4 returns it as a string
+		var v2Message struct {
+			Value string `json:"value"`
+		}
+		if err = json.Unmarshal(jsonErr.Error.Message, &v2Message); err == nil {
+			message = v2Message.Value
+		} else {
+			_ = json.Unmarshal(jsonErr.Error.Message, &message)
+		}
+		return
+	}
+	var xmlErr odataXMLError
+	if err := xml.Unmarshal(body, &xmlErr); err == nil {
+		return xmlErr.Code, xmlErr.Message
+	}
+	return "", ""
+}
@@ -1,13 +1,19 @@
 package httpclnt
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -20,15 +26,43 @@ type HTTPExecuter struct {
 	httpClient    *http.Client
 	AuthType      string
 	showLogs      bool
+	retryPolicy   *RetryPolicy
+	csrfMutex     sync.Mutex
+	csrfToken     string
+	csrfCookies   []*http.Cookie
+	initErr       error
 }
 
 // New returns an initialised HTTPExecuter instance.
 func New(oauthHost string, oauthPath string, clientId string, clientSecret string, userId string, password string, host string, scheme string, port int, showLogs bool) *HTTPExecuter {
+	return NewWithTLS(oauthHost, oauthPath, clientId, clientSecret, userId, password, host, scheme, port, showLogs, nil)
+}
+
+// NewWithTLS returns an initialised HTTPExecuter instance that uses the client certificate and CA certificates of the
+// TLS options. With a client certificate, the OAuth token is requested with the certificate instead of the client
+// secret (X.509), and without OAuth host and user ID, the client certificate authenticates the calls to the tenant.
+// If the TLS options cannot be loaded, the error is returned by all requests of the executer.
+func NewWithTLS(oauthHost string, oauthPath string, clientId string, clientSecret string, userId string, password string, host string, scheme string, port int, showLogs bool, tlsOptions *TLSOptions) *HTTPExecuter {
 	e := new(HTTPExecuter)
 	e.host = host
 	e.scheme = scheme
 	e.port = port
 	e.showLogs = showLogs
+
+	var transport http.RoundTripper
+	hasClientCert := false
+	if tlsOptions != nil {
+		tlsConfig, err := NewTLSConfig(tlsOptions)
+		if err != nil {
+			e.initErr = err
+		} else {
+			tlsTransport := http.DefaultTransport.(*http.Transport).Clone()
+			tlsTransport.TLSClientConfig = tlsConfig
+			transport = tlsTransport
+			hasClientCert = len(tlsConfig.Certificates) > 0
+		}
+	}
+
 	if oauthHost != "" {
 		if showLogs {
 			log.Debug().Msg("Initialising HTTP client with OAuth 2.0")
@@ -45,15 +79,30 @@ func New(oauthHost string, oauthPath string, clientId string, clientSecret strin
 			ClientSecret: clientSecret,
 			TokenURL:     tokenURL,
 		}
+		if hasClientCert && clientSecret == "" {
+			// X.509 client credentials - the client is authenticated by the certificate, and the client ID is sent in the body
+			conf.AuthStyle = oauth2.AuthStyleInParams
+		}
 
 		ctx := context.Background()
+		if transport != nil {
+			// Used for the token request and the calls to the tenant. Without a timeout, same as the default OAuth
+			// client, as oauth2 copies the timeout to the client of the calls to the tenant
+			ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport})
+		}
 		e.httpClient = conf.Client(ctx)
 		e.AuthType = "OAUTH"
+	} else if hasClientCert && userId == "" {
+		if showLogs {
+			log.Debug().Msg("Initialising HTTP client with client certificate")
+		}
+		e.httpClient = &http.Client{Timeout: 30 * time.Second, Transport: transport}
+		e.AuthType = "CERTIFICATE"
 	} else {
 		if showLogs {
 			log.Debug().Msg("Initialising HTTP client with Basic Authentication")
 		}
-		e.httpClient = &http.Client{Timeout: 30 * time.Second}
+		e.httpClient = &http.Client{Timeout: 30 * time.Second, Transport: transport}
 		e.basicUserId = userId
 		e.basicPassword = password
 		e.AuthType = "BASIC"
@@ -61,15 +110,91 @@ func New(oauthHost string, oauthPath string, clientId string, clientSecret strin
 	return e
 }
 
+// CsrfToken returns the CSRF token and session cookies cached for this executer.
+func (e *HTTPExecuter) CsrfToken() (string, []*http.Cookie) {
+	e.csrfMutex.Lock()
+	defer e.csrfMutex.Unlock()
+	return e.csrfToken, e.csrfCookies
+}
+
+// SetCsrfToken caches the CSRF token and session cookies so that they can be reused across modifying calls.
+func (e *HTTPExecuter) SetCsrfToken(token string, cookies []*http.Cookie) {
+	e.csrfMutex.Lock()
+	defer e.csrfMutex.Unlock()
+	e.csrfToken = token
+	e.csrfCookies = cookies
+}
+
+// ClearCsrfToken removes the cached CSRF token so that a new one is fetched for the next modifying call.
+func (e *HTTPExecuter) ClearCsrfToken() {
+	e.SetCsrfToken("", nil)
+}
+
+// SetRetryPolicy enables retries of requests that fail with transient errors.
+func (e *HTTPExecuter) SetRetryPolicy(policy *RetryPolicy) {
+	e.retryPolicy = policy
+}
+
+// ExecRequestWithCookies executes the HTTP request. Requests with idempotent methods are retried
+// according to the retry policy when they fail with transient errors.
 func (e *HTTPExecuter) ExecRequestWithCookies(method string, path string, body io.Reader, headers map[string]string, cookies []*http.Cookie) (resp *http.Response, err error) {
+	return e.execRequestWithRetry(method, path, body, headers, cookies, isIdempotent(method))
+}
 
-	url := fmt.Sprintf("%v://%v:%d%v", e.scheme, e.host, e.port, path)
+// ExecRetryableRequestWithCookies executes the HTTP request and retries it according to the retry policy
+// regardless of the method. It should only be used for requests that are safe to repeat.
+func (e *HTTPExecuter) ExecRetryableRequestWithCookies(method string, path string, body io.Reader, headers map[string]string, cookies []*http.Cookie) (resp *http.Response, err error) {
+	return e.execRequestWithRetry(method, path, body, headers, cookies, true)
+}
+
+func (e *HTTPExecuter) execRequestWithRetry(method string, path string, body io.Reader, headers map[string]string, cookies []*http.Cookie, retryable bool) (resp *http.Response, err error) {
+	maxAttempts := 1
+	if retryable && e.retryPolicy != nil && e.retryPolicy.MaxAttempts > 1 {
+		maxAttempts = e.retryPolicy.MaxAttempts
+	}
+
+	// Keep the request body so that it can be resent for subsequent attempts
+	var content []byte
+	if maxAttempts > 1 && body != nil && body != http.NoBody {
+		content, err = io.ReadAll(body)
+		if err != nil {
+			return
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		if content != nil {
+			body = bytes.NewReader(content)
+		}
+		resp, err = e.execRequest(method, path, body, headers, cookies)
+		if attempt >= maxAttempts || !isTransient(resp, err) {
+			return
+		}
+		delay := e.retryPolicy.delay(attempt, resp)
+		if err != nil {
+			log.Warn().Msgf("HTTP request %v %v failed with error %v. Retrying in %v (attempt %d of %d)", method, redactPath(path), err, delay, attempt+1, maxAttempts)
+		} else {
+			log.Warn().Msgf("HTTP request %v %v failed with response code = %d. Retrying in %v (attempt %d of %d)", method, redactPath(path), resp.StatusCode, delay, attempt+1, maxAttempts)
+			// Discard the response of the failed attempt
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		time.Sleep(delay)
+	}
+}
+
+func (e *HTTPExecuter) execRequest(method string, path string, body io.Reader, headers map[string]string, cookies []*http.Cookie) (resp *http.Response, err error) {
+	if e.initErr != nil {
+		return nil, e.initErr
+	}
+
+	reqURL := fmt.Sprintf("%v://%v:%d%v", e.scheme, e.host, e.port, path)
 	if e.showLogs {
-		log.Debug().Msgf("Executing HTTP request: %v %v", method, url)
+		log.Debug().Msgf("Executing HTTP request: %v %v", method, redactPath(reqURL))
 	}
 
 	// Create new HTTP request
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequest(method, reqURL, body)
 	if err != nil {
 		return
 	}
@@ -92,7 +217,20 @@ func (e *HTTPExecuter) ExecRequestWithCookies(method string, path string, body i
 	}
 
 	// Execute HTTP request
-	return e.httpClient.Do(req)
+	resp, err = e.httpClient.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactPath(urlErr.URL)
+	}
+	return
+}
+
+// Query parameters whose values are secrets, e.g. the password of a key pair that is uploaded
+var secretQueryRegex = regexp.MustCompile(`([?&]password=)[^&]*`)
+
+// redactPath hides the values of secret query parameters in the path so that it can be logged
+func redactPath(path string) string {
+	return secretQueryRegex.ReplaceAllString(path, "${1}***")
 }
 
 func (e *HTTPExecuter) ExecGetRequest(path string, headers map[string]string) (resp *http.Response, err error) {
@@ -115,5 +253,5 @@ func (e *HTTPExecuter) LogError(resp *http.Response, callType string) (resBody [
 		log.Warn().Msgf("Response body = %s", resBody)
 	}
 
-	return resBody, fmt.Errorf("%v call failed with response code = %d", callType, resp.StatusCode)
+	return resBody, newHTTPError(resp, callType, resBody)
 }
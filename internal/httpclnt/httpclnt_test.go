@@ -2,11 +2,14 @@ package httpclnt
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestMockOauth(t *testing.T) {
@@ -148,6 +151,120 @@ func TestMockBasicAuthIDNotFound(t *testing.T) {
 	}
 }
 
+func TestMockHTTPErrorDetails(t *testing.T) {
+	// Set up local server with mock HTTP responses
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/IntegrationPackages('Dummy')", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{ "error": { "code": "Not Found", "message": { "lang": "en", "value": "Integration package Dummy does not exist." } } }`))
+	})
+	svr := httptest.NewServer(mux)
+
+	defer svr.Close()
+
+	// Initialise HTTP executer
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummyuser", "dummypassword", host, "http", port, true)
+
+	// Execute HTTP request
+	resp, err := exe.ExecGetRequest("/api/v1/IntegrationPackages('Dummy')", nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	_, err = exe.LogError(resp, "Get IntegrationPackages by ID")
+
+	// Verify error details
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Error %v does not match ErrNotFound", err)
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Fatalf("Error %v should not match ErrConflict", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Error %v is not an HTTPError", err)
+	}
+	if httpErr.Code != "Not Found" || httpErr.Message != "Integration package Dummy does not exist." {
+		t.Fatalf("Incorrect OData error details - code = %v, message = %v", httpErr.Code, httpErr.Message)
+	}
+	if httpErr.Method != http.MethodGet || httpErr.Path != "/api/v1/IntegrationPackages('Dummy')" {
+		t.Fatalf("Incorrect request details - method = %v, path = %v", httpErr.Method, httpErr.Path)
+	}
+	if err.Error() != "Get IntegrationPackages by ID call failed with response code = 404 - Integration package Dummy does not exist." {
+		t.Fatalf("Actual error returned = %s", err.Error())
+	}
+}
+
+func TestMockRetryTransientErrors(t *testing.T) {
+	// Set up local server that is unavailable for the first two calls
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	svr := httptest.NewServer(mux)
+
+	defer svr.Close()
+
+	// Initialise HTTP executer with retries
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummyuser", "dummypassword", host, "http", port, true)
+	exe.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, Jitter: true})
+
+	// Execute HTTP request
+	resp, err := exe.ExecRequestWithCookies(http.MethodPut, "/api/v1/", strings.NewReader("content"), nil, nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("HTTP call failed with response code - %v", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, actual calls = %d", calls)
+	}
+}
+
+func TestMockNoRetryForPost(t *testing.T) {
+	// Set up local server that is always unavailable
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	svr := httptest.NewServer(mux)
+
+	defer svr.Close()
+
+	// Initialise HTTP executer with retries
+	host, port := GetHostPort(svr.URL)
+	exe := New("", "", "", "", "dummyuser", "dummypassword", host, "http", port, true)
+	exe.SetRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond})
+
+	// Execute HTTP request
+	resp, err := exe.ExecRequestWithCookies(http.MethodPost, "/api/v1/", http.NoBody, nil, nil)
+	if err != nil {
+		t.Fatalf("HTTP call failed with error - %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Fatalf("Expected single call with response code 503, actual calls = %d, response code = %d", calls, resp.StatusCode)
+	}
+
+	// Retryable requests are repeated regardless of method
+	calls = 0
+	_, _ = exe.ExecRetryableRequestWithCookies(http.MethodPost, "/api/v1/", http.NoBody, nil, nil)
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, actual calls = %d", calls)
+	}
+}
+
 func TestOauth(t *testing.T) {
 	host := os.Getenv("FLASHPIPE_TMN_HOST")
 	oauthHost := os.Getenv("FLASHPIPE_OAUTH_HOST")
@@ -185,3 +302,14 @@ func TestBasicAuth(t *testing.T) {
 		t.Fatalf("HTTP call failed with response code - %v", resp.StatusCode)
 	}
 }
+
+func TestRedactPath(t *testing.T) {
+	redacted := redactPath("/api/v1/KeyPairResources('6b6579')/$value?password=s3cr3t&update=true")
+	if redacted != "/api/v1/KeyPairResources('6b6579')/$value?password=***&update=true" {
+		t.Fatalf("Password not redacted in path - %v", redacted)
+	}
+	redacted = redactPath("/api/v1/IntegrationPackages")
+	if redacted != "/api/v1/IntegrationPackages" {
+		t.Fatalf("Path without password changed - %v", redacted)
+	}
+}
@@ -0,0 +1,84 @@
+package httpclnt
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with transient errors are retried.
+type RetryPolicy struct {
+	MaxAttempts  int           // Total number of attempts including the first one
+	InitialDelay time.Duration // Delay before the first retry, doubled for each subsequent retry
+	MaxDelay     time.Duration // Upper bound for the delay between attempts
+	Jitter       bool          // Randomise the delay to avoid retries from concurrent requests hitting the tenant at the same time
+}
+
+// NewRetryPolicy returns a RetryPolicy with delays provided in seconds.
+func NewRetryPolicy(maxAttempts int, initialDelaySeconds int, maxDelaySeconds int, jitter bool) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:  maxAttempts,
+		InitialDelay: time.Duration(initialDelaySeconds) * time.Second,
+		MaxDelay:     time.Duration(maxDelaySeconds) * time.Second,
+		Jitter:       jitter,
+	}
+}
+
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	// Honour the delay requested by the server if it is provided
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(d, p.MaxDelay)
+		}
+	}
+	d := p.InitialDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter && d > 0 {
+		// Use a random delay between half and the full computed delay
+		d = d/2 + rand.N(d/2+1)
+	}
+	return d
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
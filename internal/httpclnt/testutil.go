@@ -1,8 +1,11 @@
 package httpclnt
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"strings"
+	"testing"
 )
 
 func GetHostPort(url string) (string, int) {
@@ -10,3 +13,13 @@ func GetHostPort(url string) (string, int) {
 	i, _ := strconv.Atoi(urlParts[1])
 	return urlParts[0], i
 }
+
+// NewMockExecuter starts a local server with the mock HTTP responses of the handler, and returns an executer with
+// Basic Authentication for the server. The server is closed when the test completes.
+func NewMockExecuter(t testing.TB, handler http.Handler) (*HTTPExecuter, *httptest.Server) {
+	svr := httptest.NewServer(handler)
+	t.Cleanup(svr.Close)
+
+	host, port := GetHostPort(svr.URL)
+	return New("", "", "", "", "dummyuser", "dummypassword", host, "http", port, true), svr
+}
@@ -0,0 +1,131 @@
+package httpclnt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// TLSOptions contains the client certificate and CA certificates used for the TLS connections to the tenant and OAuth
+// token server.
+type TLSOptions struct {
+	// CertFile is a PEM file with the client certificate, or a PKCS#12 file (.p12 or .pfx) with the certificate and key
+	CertFile string
+	// KeyFile is a PEM file with the private key, not required if it is contained in CertFile
+	KeyFile string
+	// Passphrase of the PKCS#12 file or encrypted PEM private key
+	Passphrase string
+	// CABundleFile is a PEM file with the CA certificates that are trusted in addition to the system certificates
+	CABundleFile string
+}
+
+// NewTLSConfig returns the TLS configuration with the client certificate and CA certificates of the options.
+func NewTLSConfig(options *TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if options.CertFile != "" {
+		cert, err := loadClientCertificate(options.CertFile, options.KeyFile, options.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if options.CABundleFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		content, err := os.ReadFile(options.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA bundle %v: %w", options.CABundleFile, err)
+		}
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %v", options.CABundleFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+func loadClientCertificate(certFile string, keyFile string, passphrase string) (tls.Certificate, error) {
+	content, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error reading client certificate %v: %w", certFile, err)
+	}
+	ext := strings.ToLower(filepath.Ext(certFile))
+	if ext == ".p12" || ext == ".pfx" {
+		key, leaf, caCerts, err := pkcs12.DecodeChain(content, passphrase)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("error decoding PKCS#12 client certificate %v: %w", certFile, err)
+		}
+		// The CA certificates in the file are sent as the chain of the client certificate
+		cert := tls.Certificate{Certificate: [][]byte{leaf.Raw}, PrivateKey: key, Leaf: leaf}
+		for _, ca := range caCerts {
+			cert.Certificate = append(cert.Certificate, ca.Raw)
+		}
+		return cert, nil
+	}
+
+	// The private key can be in the same PEM file as the certificate
+	keyContent := content
+	if keyFile != "" {
+		keyContent, err = os.ReadFile(keyFile)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("error reading client key %v: %w", keyFile, err)
+		}
+	}
+	keyContent, err = decryptPEMKey(keyContent, passphrase)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	cert, err := tls.X509KeyPair(content, keyContent)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid PEM client certificate %v: %w", certFile, err)
+	}
+	return cert, nil
+}
+
+// decryptPEMKey returns the PEM content with the encrypted private key decrypted by the passphrase
+func decryptPEMKey(content []byte, passphrase string) ([]byte, error) {
+	var output []byte
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return output, nil
+		}
+		// PKCS#8 encryption is used by OpenSSL 3 for private keys encrypted with e.g. openssl pkey -aes256
+		if block.Type == "ENCRYPTED PRIVATE KEY" {
+			if passphrase == "" {
+				return nil, fmt.Errorf("private key is encrypted, but no passphrase is provided")
+			}
+			key, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(passphrase))
+			if err != nil {
+				return nil, fmt.Errorf("error decrypting private key: %w", err)
+			}
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("error decrypting private key: %w", err)
+			}
+			block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		}
+		// Legacy PEM encryption (Proc-Type header) is used by OpenSSL 1.x and by openssl rsa -traditional
+		if x509.IsEncryptedPEMBlock(block) {
+			if passphrase == "" {
+				return nil, fmt.Errorf("private key is encrypted, but no passphrase is provided")
+			}
+			der, err := x509.DecryptPEMBlock(block, []byte(passphrase))
+			if err != nil {
+				return nil, fmt.Errorf("error decrypting private key: %w", err)
+			}
+			block = &pem.Block{Type: block.Type, Bytes: der}
+		}
+		output = append(output, pem.EncodeToMemory(block)...)
+	}
+}
@@ -0,0 +1,151 @@
+package httpclnt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const certificatesDir = "../../test/testdata/certificates"
+
+func TestNewTLSConfig_ClientCertificateFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *TLSOptions
+	}{
+		{"PEM with separate key", &TLSOptions{CertFile: certificatesDir + "/client.pem", KeyFile: certificatesDir + "/client.key"}},
+		{"PEM with encrypted PKCS#8 key", &TLSOptions{CertFile: certificatesDir + "/client.pem", KeyFile: certificatesDir + "/client-encrypted.key", Passphrase: "secret"}},
+		{"PEM with legacy encrypted key", &TLSOptions{CertFile: certificatesDir + "/client.pem", KeyFile: certificatesDir + "/client-legacy-encrypted.key", Passphrase: "secret"}},
+		{"PEM with certificate and key", &TLSOptions{CertFile: combinedPEM(t)}},
+		{"PKCS#12 with AES and SHA-256 MAC", &TLSOptions{CertFile: certificatesDir + "/client.p12", Passphrase: "secret"}},
+		{"PKCS#12 with legacy 3DES and SHA-1 MAC", &TLSOptions{CertFile: certificatesDir + "/client-legacy.p12", Passphrase: "secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewTLSConfig(tt.options)
+			assert.NoError(t, err)
+			assert.Len(t, config.Certificates, 1)
+			cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+			assert.NoError(t, err)
+			assert.Equal(t, "flashpipe-client", cert.Subject.CommonName)
+		})
+	}
+}
+
+func TestNewTLSConfig_Errors(t *testing.T) {
+	_, err := NewTLSConfig(&TLSOptions{CertFile: certificatesDir + "/client.pem", KeyFile: certificatesDir + "/client-encrypted.key"})
+	assert.EqualError(t, err, "private key is encrypted, but no passphrase is provided")
+
+	_, err = NewTLSConfig(&TLSOptions{CertFile: certificatesDir + "/client.pem", KeyFile: certificatesDir + "/client-encrypted.key", Passphrase: "wrong"})
+	assert.EqualError(t, err, "error decrypting private key: pkcs8: incorrect password")
+
+	_, err = NewTLSConfig(&TLSOptions{CertFile: certificatesDir + "/client.p12", Passphrase: "wrong"})
+	assert.ErrorContains(t, err, "error decoding PKCS#12 client certificate")
+
+	_, err = NewTLSConfig(&TLSOptions{CABundleFile: certificatesDir + "/client.key"})
+	assert.ErrorContains(t, err, "no PEM certificates found in CA bundle")
+}
+
+func TestMockClientCertificate(t *testing.T) {
+	svr := newMTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Write([]byte(`{ "d": { "Id": "Dummy" } }`))
+	}))
+
+	host, port := getTLSHostPort(svr.URL)
+	exe := NewWithTLS("", "", "", "", "", "", host, "https", port, true, clientTLSOptions(t, svr))
+	assert.Equal(t, "CERTIFICATE", exe.AuthType)
+
+	resp, err := exe.ExecGetRequest("/api/v1/IntegrationDesigntimeArtifacts(Id='Dummy',Version='Active')", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestMockX509OAuth(t *testing.T) {
+	const clientId = "dummyid"
+	const token = "token123"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		// The client is authenticated by its certificate instead of a client secret
+		if r.Header.Get("Authorization") != "" || r.FormValue("client_id") != clientId || r.FormValue("client_secret") != "" {
+			http.Error(w, "Invalid client authentication", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(fmt.Sprintf(`{ "access_token": "%v" }`, token)))
+	})
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != fmt.Sprintf("Bearer %v", token) {
+			http.Error(w, "Invalid token for endpoint authorization", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{ "d": { "Id": "Dummy" } }`))
+	})
+	svr := newMTLSServer(t, mux)
+
+	host, port := getTLSHostPort(svr.URL)
+	exe := NewWithTLS(host, "/oauth/token", clientId, "", "", "", host, "https", port, true, clientTLSOptions(t, svr))
+	assert.Equal(t, "OAUTH", exe.AuthType)
+	// Same as without TLS options, the OAuth client has no timeout
+	assert.Zero(t, exe.httpClient.Timeout)
+
+	resp, err := exe.ExecGetRequest("/api/v1/IntegrationDesigntimeArtifacts(Id='Dummy',Version='Active')", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestNewWithTLS_InvalidCertificateFailsRequests(t *testing.T) {
+	exe := NewWithTLS("", "", "", "", "", "", "localhost", "https", 443, true, &TLSOptions{CertFile: certificatesDir + "/missing.pem"})
+
+	_, err := exe.ExecGetRequest("/api/v1/", nil)
+	assert.ErrorContains(t, err, "error reading client certificate")
+}
+
+// newMTLSServer returns a TLS server that requires the client certificate of the test data
+func newMTLSServer(t *testing.T, handler http.Handler) *httptest.Server {
+	content, err := os.ReadFile(certificatesDir + "/client.pem")
+	assert.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(content)
+
+	svr := httptest.NewUnstartedServer(handler)
+	svr.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	svr.StartTLS()
+	t.Cleanup(svr.Close)
+	return svr
+}
+
+// clientTLSOptions returns the options with the client certificate, and the certificate of the server as CA bundle
+func clientTLSOptions(t *testing.T, svr *httptest.Server) *TLSOptions {
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: svr.Certificate().Raw}), 0644)
+	assert.NoError(t, err)
+	return &TLSOptions{CertFile: certificatesDir + "/client.p12", Passphrase: "secret", CABundleFile: caBundle}
+}
+
+func combinedPEM(t *testing.T) string {
+	cert, err := os.ReadFile(certificatesDir + "/client.pem")
+	assert.NoError(t, err)
+	key, err := os.ReadFile(certificatesDir + "/client.key")
+	assert.NoError(t, err)
+	combined := filepath.Join(t.TempDir(), "client.pem")
+	assert.NoError(t, os.WriteFile(combined, append(cert, key...), 0644))
+	return combined
+}
+
+func getTLSHostPort(svrURL string) (string, int) {
+	u, _ := url.Parse(svrURL)
+	port, _ := strconv.Atoi(u.Port())
+	return u.Hostname(), port
+}
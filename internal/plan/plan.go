@@ -0,0 +1,79 @@
+package plan
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Action is a change that would be made during a dry run
+type Action string
+
+const (
+	Create          Action = "create"
+	Update          Action = "update"
+	Delete          Action = "delete"
+	UpdateParameter Action = "update parameter"
+	Undeploy        Action = "undeploy"
+	Deploy          Action = "deploy"
+)
+
+// Step is a single change of the plan
+type Step struct {
+	Action       Action
+	ArtifactType string
+	Id           string
+	Target       string // tenant or git
+	Detail       string
+}
+
+func (s Step) String() string {
+	msg := fmt.Sprintf("%v %v %v in %v", s.Action, s.ArtifactType, s.Id, s.Target)
+	if s.Detail != "" {
+		msg = fmt.Sprintf("%v - %v", msg, s.Detail)
+	}
+	return msg
+}
+
+// Plan records the changes that would be made when commands are executed with --dry-run. It is safe for concurrent use.
+type Plan struct {
+	mu    sync.Mutex
+	steps []Step
+}
+
+// New returns an empty Plan.
+func New() *Plan {
+	return new(Plan)
+}
+
+// Add records a change in the plan instead of executing it.
+func (p *Plan) Add(action Action, artifactType string, id string, target string, detail string) {
+	step := Step{Action: action, ArtifactType: artifactType, Id: id, Target: target, Detail: detail}
+	log.Info().Msgf("📝 Dry run - would %v", step)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps = append(p.steps, step)
+}
+
+// Steps returns the recorded changes in the order they were added.
+func (p *Plan) Steps() []Step {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Step(nil), p.steps...)
+}
+
+// Log prints the plan.
+func (p *Plan) Log() {
+	steps := p.Steps()
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	if len(steps) == 0 {
+		log.Info().Msg("📢 Dry run completed - no changes would be made")
+		return
+	}
+	log.Info().Msgf("📢 Dry run completed - %d change(s) would be made", len(steps))
+	for i, step := range steps {
+		log.Info().Msgf("%d. %v", i+1, step)
+	}
+}
@@ -0,0 +1,18 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSteps(t *testing.T) {
+	p := New()
+	p.Add(Update, "Integration", "IFlow1", "tenant", "to version 1.0.1")
+	p.Add(UpdateParameter, "Integration", "IFlow1", "tenant", "Host from a to b")
+
+	steps := p.Steps()
+	assert.Equal(t, 2, len(steps), "Expected number of steps = 2")
+	assert.Equal(t, "update Integration IFlow1 in tenant - to version 1.0.1", steps[0].String())
+	assert.Equal(t, "update parameter Integration IFlow1 in tenant - Host from a to b", steps[1].String())
+}
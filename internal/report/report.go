@@ -0,0 +1,262 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// Action is the outcome of processing an artifact
+type Action string
+
+const (
+	Created    Action = "created"
+	Updated    Action = "updated"
+	Unchanged  Action = "unchanged"
+	Deleted    Action = "deleted"
+	Deployed   Action = "deployed"
+	Undeployed Action = "undeployed"
+	Skipped    Action = "skipped"
+	Failed     Action = "failed"
+)
+
+// Supported formats of the report file
+const (
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// Entry is the result of processing a single artifact
+type Entry struct {
+	ArtifactType  string `json:"artifactType"`
+	Id            string `json:"id"`
+	Target        string `json:"target"` // tenant or Git
+	Action        Action `json:"action"`
+	VersionBefore string `json:"versionBefore,omitempty"`
+	VersionAfter  string `json:"versionAfter,omitempty"`
+	DurationMs    int64  `json:"durationMs"`
+	Diff          string `json:"diff,omitempty"`    // Summary of the differences that caused the change
+	Message       string `json:"message,omitempty"` // Additional information on the action, e.g. why it was skipped
+	Error         string `json:"error,omitempty"`
+	ErrorDetails  any    `json:"errorDetails,omitempty"` // Structured error information from the tenant
+}
+
+// Report records the result of each artifact processed by a command. It is safe for concurrent use, and all methods
+// can be called on a nil Report, in which case nothing is recorded.
+type Report struct {
+	mu        sync.Mutex
+	command   string
+	dryRun    bool
+	startTime time.Time
+	entries   []Entry
+}
+
+// New returns an empty Report for the command, e.g. "sync" or "snapshot restore".
+func New(command string) *Report {
+	return &Report{command: command, startTime: time.Now()}
+}
+
+// SetDryRun marks the report as a dry run, where the actions were planned but not executed.
+func (r *Report) SetDryRun() {
+	if r == nil {
+		return
+	}
+	r.dryRun = true
+}
+
+// Add records the entry, with its duration measured from start.
+func (r *Report) Add(start time.Time, entry Entry) {
+	if r == nil {
+		return
+	}
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// AddError records a failed entry for the error.
+func (r *Report) AddError(start time.Time, entry Entry, err error) {
+	entry.Action = Failed
+	entry.Error = err.Error()
+	r.Add(start, entry)
+}
+
+// Entries returns the recorded entries in the order they were added.
+func (r *Report) Entries() []Entry {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+type jsonReport struct {
+	Command    string  `json:"command"`
+	DryRun     bool    `json:"dryRun"`
+	StartTime  string  `json:"startTime"`
+	DurationMs int64   `json:"durationMs"`
+	Success    bool    `json:"success"`
+	Error      string  `json:"error,omitempty"`
+	Artifacts  []Entry `json:"artifacts"`
+}
+
+// JSON returns the report in JSON format. cmdErr is the error that the command ended with, if any.
+func (r *Report) JSON(cmdErr error) ([]byte, error) {
+	content := jsonReport{
+		Command:    r.command,
+		DryRun:     r.dryRun,
+		StartTime:  r.startTime.Format(time.RFC3339),
+		DurationMs: time.Since(r.startTime).Milliseconds(),
+		Success:    cmdErr == nil,
+		Artifacts:  r.Entries(),
+	}
+	if cmdErr != nil {
+		content.Error = cmdErr.Error()
+	}
+	if content.Artifacts == nil {
+		content.Artifacts = []Entry{}
+	}
+	output, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return output, nil
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// JUnit returns the report in JUnit XML format, with a test case for each artifact. An error of the command that is
+// not related to a specific artifact is reported as an additional failed test case.
+func (r *Report) JUnit(cmdErr error) ([]byte, error) {
+	name := "flashpipe " + r.command
+	if r.dryRun {
+		name += " (dry run)"
+	}
+	suite := junitTestSuite{
+		Name:      name,
+		Time:      seconds(time.Since(r.startTime).Milliseconds()),
+		Timestamp: r.startTime.Format("2006-01-02T15:04:05"),
+	}
+	artifactFailed := false
+	for _, entry := range r.Entries() {
+		testCase := junitTestCase{
+			ClassName: entry.ArtifactType,
+			Name:      entry.Id,
+			Time:      seconds(entry.DurationMs),
+			SystemOut: entry.summary(),
+		}
+		switch entry.Action {
+		case Failed:
+			testCase.Failure = &junitFailure{Message: entry.Error, Content: entry.Error}
+			suite.Failures++
+			artifactFailed = true
+		case Skipped:
+			testCase.Skipped = &struct{}{}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	if cmdErr != nil && !artifactFailed {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: "command",
+			Name:      r.command,
+			Time:      suite.Time,
+			Failure:   &junitFailure{Message: cmdErr.Error(), Content: cmdErr.Error()},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	output, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+// Write saves the report in the format to the file. cmdErr is the error that the command ended with, if any.
+func (r *Report) Write(path string, format string, cmdErr error) error {
+	var content []byte
+	var err error
+	switch format {
+	case FormatJSON:
+		content, err = r.JSON(cmdErr)
+	case FormatJUnit:
+		content, err = r.JUnit(cmdErr)
+	default:
+		return fmt.Errorf("invalid report format %v", format)
+	}
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		err = os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	err = os.WriteFile(path, content, 0644)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
+
+func (e Entry) summary() string {
+	msg := fmt.Sprintf("%v %v in %v", e.Action, e.ArtifactType, e.Target)
+	switch {
+	case e.VersionBefore != "" && e.VersionAfter != "" && e.VersionBefore != e.VersionAfter:
+		msg = fmt.Sprintf("%v (version %v -> %v)", msg, e.VersionBefore, e.VersionAfter)
+	case e.VersionAfter != "":
+		msg = fmt.Sprintf("%v (version %v)", msg, e.VersionAfter)
+	case e.VersionBefore != "":
+		msg = fmt.Sprintf("%v (version %v)", msg, e.VersionBefore)
+	}
+	if e.Message != "" {
+		msg = fmt.Sprintf("%v - %v", msg, e.Message)
+	}
+	if e.Diff != "" {
+		msg = fmt.Sprintf("%v - %v", msg, e.Diff)
+	}
+	return msg
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportJSON(t *testing.T) {
+	r := New("deploy")
+	r.Add(time.Now(), Entry{ArtifactType: "Integration", Id: "IFlow1", Target: "tenant", Action: Deployed, VersionBefore: "1.0.0", VersionAfter: "1.0.1"})
+	r.AddError(time.Now(), Entry{ArtifactType: "Integration", Id: "IFlow2", Target: "tenant"}, fmt.Errorf("deployment failed"))
+
+	output, err := r.JSON(fmt.Errorf("1 of 2 artifact(s) failed to deploy"))
+	assert.NoError(t, err)
+
+	var content map[string]any
+	assert.NoError(t, json.Unmarshal(output, &content))
+	assert.Equal(t, "deploy", content["command"])
+	assert.Equal(t, false, content["success"])
+	artifacts := content["artifacts"].([]any)
+	if assert.Equal(t, 2, len(artifacts), "Expected number of artifacts = 2") {
+		assert.Equal(t, "1.0.1", artifacts[0].(map[string]any)["versionAfter"])
+		assert.Equal(t, "failed", artifacts[1].(map[string]any)["action"])
+		assert.Equal(t, "deployment failed", artifacts[1].(map[string]any)["error"])
+	}
+}
+
+func TestReportJUnit(t *testing.T) {
+	r := New("sync")
+	r.Add(time.Now(), Entry{ArtifactType: "Integration", Id: "IFlow1", Target: "tenant", Action: Updated, Diff: "parameters changed: Host"})
+	r.Add(time.Now(), Entry{ArtifactType: "Integration", Id: "IFlow2", Target: "Git", Action: Skipped, Message: "draft version"})
+
+	output, err := r.JUnit(nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(output), `<testsuite name="flashpipe sync" tests="2" failures="0" skipped="1"`)
+	assert.Contains(t, string(output), `<testcase classname="Integration" name="IFlow1"`)
+	assert.Contains(t, string(output), "<system-out>updated Integration in tenant - parameters changed: Host</system-out>")
+	assert.Contains(t, string(output), "<system-out>skipped Integration in Git - draft version</system-out>")
+
+	// Command errors without a failed artifact are reported as a separate test case
+	output, err = r.JUnit(fmt.Errorf("package not found"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(output), `tests="3" failures="1"`)
+	assert.Contains(t, string(output), `<failure message="package not found">`)
+}
+
+func TestReportWrite(t *testing.T) {
+	path := t.TempDir() + "/reports/report.json"
+	assert.NoError(t, New("snapshot").Write(path, FormatJSON, nil))
+	_, err := os.Stat(path)
+	assert.NoError(t, err)
+
+	assert.Error(t, New("snapshot").Write(path, "yaml", nil))
+}
+
+func TestNilReport(t *testing.T) {
+	var r *Report
+	r.Add(time.Now(), Entry{Id: "IFlow1"})
+	assert.Nil(t, r.Entries())
+}
@@ -0,0 +1,265 @@
+package schedule
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// Schedule is the structured representation of a custom:schedule parameter of a timer
+type Schedule struct {
+	FireNow  bool      // Run once immediately after deployment
+	Crons    []string  // Quartz cron expressions (seconds, minutes, hours, day of month, month, day of week, year)
+	TimeZone string    // Time zone ID, e.g. Europe/Berlin
+	OnDate   string    // Date in yyyy-MM-dd format when the schedule only runs on a specific date
+	Interval *Interval // Recurring interval within a day
+}
+
+// Interval is a recurring interval (in minutes) between the from and to hours of a day
+type Interval struct {
+	EveryMinutes int
+	FromHour     int
+	ToHour       int
+}
+
+type row struct {
+	Cells []string `xml:"cell"`
+}
+
+var timeZonePattern = regexp.MustCompile(`\(([^()]+)\)\s*$`)
+
+// IsXML returns true if the value is in the SAP schedule XML format
+func IsXML(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "<row>")
+}
+
+// ParseXML parses a value in the SAP schedule XML format, e.g.
+// <row><cell>triggerType</cell><cell>cron</cell></row><row><cell>schedule1</cell><cell>0+0+1+?+*+*+*&amp;trigger.timeZone=Etc/GMT</cell></row>
+func ParseXML(value string) (*Schedule, error) {
+	var content struct {
+		Rows []row `xml:"row"`
+	}
+	err := xml.Unmarshal([]byte("<root>"+value+"</root>"), &content)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf("invalid schedule value %v: %w", value, err), 0)
+	}
+	cells := map[string]string{}
+	for _, r := range content.Rows {
+		if len(r.Cells) == 2 {
+			cells[r.Cells[0]] = strings.TrimSpace(r.Cells[1])
+		}
+	}
+
+	s := &Schedule{}
+	if cells["triggerType"] == "fireNow" || cells["fireNow"] == "true" {
+		s.FireNow = true
+		return s, nil
+	}
+	if m := timeZonePattern.FindStringSubmatch(cells["timeZone"]); m != nil {
+		s.TimeZone = strings.TrimSpace(m[1])
+	}
+	noOfSchedules, _ := strconv.Atoi(cells["noOfSchedules"])
+	for i := 1; i <= max(noOfSchedules, 1); i++ {
+		value, found := cells[fmt.Sprintf("schedule%d", i)]
+		if !found {
+			continue
+		}
+		expression, attributes, _ := strings.Cut(value, "&")
+		s.Crons = append(s.Crons, strings.Join(strings.Fields(strings.ReplaceAll(expression, "+", " ")), " "))
+		for _, attribute := range strings.Split(attributes, "&") {
+			if tz, found := strings.CutPrefix(attribute, "trigger.timeZone="); found && s.TimeZone == "" {
+				s.TimeZone = tz
+			}
+		}
+	}
+	if len(s.Crons) == 0 {
+		return nil, errors.Wrap(fmt.Errorf("no schedule found in value %v", value), 0)
+	}
+	if cells["dateType"] == "ON_DATE" {
+		s.OnDate = onDateFromCron(s.Crons[0])
+	}
+	every, _ := strconv.Atoi(cells["OnEveryMinute"])
+	if every > 0 && cells["fromInterval"] != "" && cells["toInterval"] != "" {
+		from, _ := strconv.Atoi(cells["fromInterval"])
+		to, _ := strconv.Atoi(cells["toInterval"])
+		s.Interval = &Interval{EveryMinutes: every, FromHour: from, ToHour: to}
+	}
+	return s, nil
+}
+
+func onDateFromCron(cron string) string {
+	fields := strings.Fields(cron)
+	if len(fields) < 7 {
+		return ""
+	}
+	day, errDay := strconv.Atoi(fields[3])
+	month, errMonth := strconv.Atoi(fields[4])
+	year, errYear := strconv.Atoi(fields[6])
+	if errDay != nil || errMonth != nil || errYear != nil {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+}
+
+// ParseDefinition parses a readable schedule definition used in parameters.prop. The definition consists of
+// semicolon separated entries, e.g.
+//
+//	fireNow
+//	cron=0 0 1 ? * * *;timeZone=Europe/Berlin
+//	onDate=2025-12-31;time=10:30;timeZone=Etc/GMT
+//	interval=15;from=8;to=18;timeZone=Etc/GMT
+func ParseDefinition(definition string) (*Schedule, error) {
+	s := &Schedule{}
+	var onDate, onTime string
+	for _, entry := range strings.Split(definition, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if entry == "fireNow" {
+			s.FireNow = true
+			continue
+		}
+		key, value, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid entry %v in schedule definition %v", entry, definition)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		var err error
+		switch key {
+		case "cron":
+			s.Crons = append(s.Crons, strings.Join(strings.Fields(value), " "))
+		case "timeZone":
+			s.TimeZone = value
+		case "onDate":
+			onDate = value
+		case "time":
+			onTime = value
+		case "interval", "from", "to":
+			if s.Interval == nil {
+				s.Interval = &Interval{ToHour: 24}
+			}
+			var number int
+			number, err = strconv.Atoi(value)
+			switch key {
+			case "interval":
+				s.Interval.EveryMinutes = number
+			case "from":
+				s.Interval.FromHour = number
+			case "to":
+				s.Interval.ToHour = number
+			}
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %v in schedule definition %v: %w", entry, definition, err)
+		}
+	}
+	if s.FireNow {
+		return s, nil
+	}
+
+	if onDate != "" {
+		t, err := time.Parse("2006-01-02 15:04", strings.TrimSpace(onDate+" "+withDefault(onTime, "00:00")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid onDate/time in schedule definition %v: %w", definition, err)
+		}
+		s.OnDate = t.Format("2006-01-02")
+		s.Crons = append(s.Crons, fmt.Sprintf("0 %d %d %d %d ? %d", t.Minute(), t.Hour(), t.Day(), int(t.Month()), t.Year()))
+	}
+	if s.Interval != nil {
+		i := s.Interval
+		if i.EveryMinutes < 1 || i.EveryMinutes > 60 || i.FromHour < 0 || i.ToHour > 24 || i.FromHour >= i.ToHour {
+			return nil, fmt.Errorf("invalid interval in schedule definition %v", definition)
+		}
+		s.Crons = append(s.Crons, fmt.Sprintf("0 0/%d %d-%d ? * * *", i.EveryMinutes, i.FromHour, i.ToHour-1))
+	}
+	if len(s.Crons) == 0 {
+		return nil, fmt.Errorf("no schedule found in schedule definition %v", definition)
+	}
+	if s.TimeZone == "" {
+		s.TimeZone = "Etc/GMT"
+	}
+	return s, nil
+}
+
+func withDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// Parse parses a value that is either in the SAP schedule XML format or a readable schedule definition
+func Parse(value string) (*Schedule, error) {
+	if IsXML(value) {
+		return ParseXML(value)
+	}
+	return ParseDefinition(value)
+}
+
+// Equal returns true if both schedules trigger at the same times
+func (s *Schedule) Equal(other *Schedule) bool {
+	if s.FireNow || other.FireNow {
+		return s.FireNow == other.FireNow
+	}
+	return s.TimeZone == other.TimeZone && slices.Equal(s.Crons, other.Crons)
+}
+
+// String returns the readable schedule definition
+func (s *Schedule) String() string {
+	if s.FireNow {
+		return "fireNow"
+	}
+	var entries []string
+	for _, cron := range s.Crons {
+		entries = append(entries, "cron="+cron)
+	}
+	if s.TimeZone != "" {
+		entries = append(entries, "timeZone="+s.TimeZone)
+	}
+	return strings.Join(entries, ";")
+}
+
+// XML returns the value in the SAP schedule XML format
+func (s *Schedule) XML() string {
+	var b strings.Builder
+	writeRow := func(key string, value string) {
+		b.WriteString("<row><cell>")
+		_ = xml.EscapeText(&b, []byte(key))
+		b.WriteString("</cell><cell>")
+		_ = xml.EscapeText(&b, []byte(value))
+		b.WriteString("</cell></row>")
+	}
+	if s.FireNow {
+		writeRow("triggerType", "fireNow")
+		return b.String()
+	}
+
+	dateType := "ADVANCED"
+	if s.OnDate != "" {
+		dateType = "ON_DATE"
+	}
+	writeRow("dateType", dateType)
+	if s.Interval != nil {
+		writeRow("OnEveryMinute", strconv.Itoa(s.Interval.EveryMinutes))
+		writeRow("fromInterval", strconv.Itoa(s.Interval.FromHour))
+		writeRow("toInterval", strconv.Itoa(s.Interval.ToHour))
+	}
+	writeRow("timeZone", fmt.Sprintf("(%v)", s.TimeZone))
+	writeRow("throwExceptionOnExpiry", "true")
+	for i, cron := range s.Crons {
+		writeRow(fmt.Sprintf("schedule%d", i+1), fmt.Sprintf("%v&trigger.timeZone=%v", strings.ReplaceAll(cron, " ", "+"), s.TimeZone))
+	}
+	writeRow("triggerType", "cron")
+	writeRow("noOfSchedules", strconv.Itoa(len(s.Crons)))
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tenantValue = `<row><cell>dateType</cell><cell>DAILY</cell></row><row><cell>secondValue</cell><cell>0</cell></row><row><cell>minutesValue</cell><cell>0</cell></row><row><cell>hourValue</cell><cell>1</cell></row><row><cell>toInterval</cell><cell>24</cell></row><row><cell>fromInterval</cell><cell>0</cell></row><row><cell>OnEveryMinute</cell><cell>0</cell></row><row><cell>timeZone</cell><cell>( UTC 1:00 ) Central European Standard Time(Europe/Berlin)</cell></row><row><cell>throwExceptionOnExpiry</cell><cell>true</cell></row><row><cell>schedule1</cell><cell>0+0+1+?+*+*+*&amp;trigger.timeZone=Europe/Berlin</cell></row><row><cell>triggerType</cell><cell>cron</cell></row><row><cell>noOfSchedules</cell><cell>1</cell></row>`
+
+func TestParseXML(t *testing.T) {
+	s, err := ParseXML(tenantValue)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0 0 1 ? * * *"}, s.Crons)
+	assert.Equal(t, "Europe/Berlin", s.TimeZone)
+	assert.Nil(t, s.Interval)
+	assert.Equal(t, "cron=0 0 1 ? * * *;timeZone=Europe/Berlin", s.String())
+}
+
+func TestParseXML_FireNow(t *testing.T) {
+	s, err := ParseXML(`<row><cell>triggerType</cell><cell>fireNow</cell></row>`)
+
+	assert.NoError(t, err)
+	assert.True(t, s.FireNow)
+}
+
+func TestParseXML_Invalid(t *testing.T) {
+	_, err := ParseXML(`<row><cell>dateType</cell><cell>DAILY</cell></row>`)
+
+	assert.Error(t, err)
+}
+
+func TestParseDefinition_Cron(t *testing.T) {
+	s, err := ParseDefinition("cron=0  0 1 ? * * *; timeZone=Europe/Berlin")
+
+	assert.NoError(t, err)
+	tenant, _ := ParseXML(tenantValue)
+	assert.True(t, s.Equal(tenant), "Expected schedule to match tenant value")
+}
+
+func TestParseDefinition_OnDate(t *testing.T) {
+	s, err := ParseDefinition("onDate=2025-12-31;time=10:30")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0 30 10 31 12 ? 2025"}, s.Crons)
+	assert.Equal(t, "Etc/GMT", s.TimeZone)
+
+	roundTrip, err := ParseXML(s.XML())
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-12-31", roundTrip.OnDate)
+	assert.True(t, s.Equal(roundTrip), "Expected generated XML to parse to the same schedule")
+}
+
+func TestParseDefinition_Interval(t *testing.T) {
+	s, err := ParseDefinition("interval=15;from=8;to=18;timeZone=Asia/Singapore")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0 0/15 8-17 ? * * *"}, s.Crons)
+
+	roundTrip, err := ParseXML(s.XML())
+	assert.NoError(t, err)
+	assert.Equal(t, &Interval{EveryMinutes: 15, FromHour: 8, ToHour: 18}, roundTrip.Interval)
+	assert.True(t, s.Equal(roundTrip), "Expected generated XML to parse to the same schedule")
+}
+
+func TestParseDefinition_Invalid(t *testing.T) {
+	_, err := ParseDefinition("interval=90")
+	assert.Error(t, err)
+
+	_, err = ParseDefinition("every=5")
+	assert.Error(t, err)
+
+	_, err = ParseDefinition("timeZone=Etc/GMT")
+	assert.Error(t, err)
+}
+
+func TestParse_Raw(t *testing.T) {
+	s, err := Parse(tenantValue)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", s.TimeZone)
+}
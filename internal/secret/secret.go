@@ -0,0 +1,119 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// Encrypted values have a FlashPipe-specific format that can only be decrypted with
+// the key used by security-material encrypt, e.g. ENC[AES256_GCM,data:Tr7o=,iv:1=,tag:2=,type:str]
+var encryptedRegex = regexp.MustCompile(`^ENC\[AES256_GCM,data:([^,]*),iv:([^,]+),tag:([^,]+),type:str\]$`)
+
+// References to environment variables have the format ${NAME}
+var envRegex = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// ParseKey decodes a base64 encoded 256-bit key, e.g. generated with openssl rand -base64 32
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("Key must be a base64 encoded 256-bit value")
+	}
+	return key, nil
+}
+
+// IsEncrypted returns true if the value is encrypted
+func IsEncrypted(value string) bool {
+	return encryptedRegex.MatchString(value)
+}
+
+// IsEnvReference returns true if the value references an environment variable
+func IsEnvReference(value string) bool {
+	return envRegex.MatchString(value)
+}
+
+// Encrypt encrypts the value with AES-256-GCM
+func Encrypt(value string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(iv); err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	sealed := gcm.Seal(nil, iv, []byte(value), nil)
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%v,iv:%v,tag:%v,type:str]", encode(data), encode(iv), encode(tag)), nil
+}
+
+// Decrypt decrypts a value that was encrypted with Encrypt
+func Decrypt(value string, key []byte) (string, error) {
+	matches := encryptedRegex.FindStringSubmatch(value)
+	if matches == nil {
+		return "", fmt.Errorf("Value is not in encrypted format")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	var parts [3][]byte
+	for i := range parts {
+		parts[i], err = base64.StdEncoding.DecodeString(matches[i+1])
+		if err != nil {
+			return "", fmt.Errorf("Encrypted value is not valid base64")
+		}
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+	if len(iv) != gcm.NonceSize() {
+		return "", fmt.Errorf("Encrypted value has invalid iv")
+	}
+	plain, err := gcm.Open(nil, iv, append(data, tag...), nil)
+	if err != nil {
+		// Do not include the value in the error
+		return "", fmt.Errorf("Decryption failed, the key does not match the encrypted value")
+	}
+	return string(plain), nil
+}
+
+// Resolve returns the value of an environment variable reference ${NAME}, or the decrypted value of an encrypted value.
+// Any other value is returned unchanged. key is only required for encrypted values.
+func Resolve(value string, key []byte) (string, error) {
+	if matches := envRegex.FindStringSubmatch(value); matches != nil {
+		resolved, ok := os.LookupEnv(matches[1])
+		if !ok {
+			return "", fmt.Errorf("Environment variable %v is not set", matches[1])
+		}
+		return resolved, nil
+	}
+	if IsEncrypted(value) {
+		if key == nil {
+			return "", fmt.Errorf("Key is required to decrypt encrypted values")
+		}
+		return Decrypt(value, key)
+	}
+	return value, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return gcm, nil
+}
+
+func encode(b []byte) string {
+	return base64.StdEncoding.EncodeToString(b)
+}
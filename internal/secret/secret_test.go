@@ -0,0 +1,65 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := ParseKey(testKey)
+	assert.NoError(t, err)
+
+	encrypted, err := Encrypt("s3cr3t", key)
+
+	assert.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "s3cr3t")
+	decrypted, err := Decrypt(encrypted, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", decrypted)
+}
+
+func TestDecrypt_WrongKey(t *testing.T) {
+	key, _ := ParseKey(testKey)
+	otherKey, _ := ParseKey("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+	encrypted, _ := Encrypt("s3cr3t", key)
+
+	_, err := Decrypt(encrypted, otherKey)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "Decryption failed, the key does not match the encrypted value", err.Error())
+	}
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	_, err := ParseKey("dG9vc2hvcnQ=")
+
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("FLASHPIPE_TEST_SECRET", "fromenv")
+	key, _ := ParseKey(testKey)
+	encrypted, _ := Encrypt("fromfile", key)
+
+	value, err := Resolve("${FLASHPIPE_TEST_SECRET}", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "fromenv", value)
+
+	value, err = Resolve(encrypted, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "fromfile", value)
+
+	value, err = Resolve("plain", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", value)
+
+	_, err = Resolve("${FLASHPIPE_TEST_SECRET_NOT_SET}", nil)
+	assert.Error(t, err)
+
+	_, err = Resolve(encrypted, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,415 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
+	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/str"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Layout of a partner directory in Git. String parameters, alternative partners and authorized users are stored in the
+// partner file, and each binary parameter is stored as <id>.<content type> in the binary directory.
+const (
+	partnerFile         = "partner.json"
+	partnerBinaryDir    = "Binary"
+	partnerArtifactType = "partner"
+)
+
+type PartnerDirectoryGitSynchroniser struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+// NewPartnerDirectoryGitSynchroniser returns an initialised PartnerDirectoryGitSynchroniser instance.
+func NewPartnerDirectoryGitSynchroniser(exe *httpclnt.HTTPExecuter) Syncer {
+	s := new(PartnerDirectoryGitSynchroniser)
+	s.exe = exe
+	return s
+}
+
+func (s *PartnerDirectoryGitSynchroniser) Exec(request Request) error {
+	log.Info().Msg("Sync Partner Directory content to Git")
+
+	partners, err := api.NewPartnerDirectory(s.exe).GetAll()
+	if err != nil {
+		return err
+	}
+
+	// Create temp directories in working dir
+	targetRootDir := fmt.Sprintf("%v/download", request.WorkDir)
+	err = os.MkdirAll(targetRootDir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	// Process through the partners
+	var tenantIds []string
+	for _, partner := range partners {
+		tenantIds = append(tenantIds, partner.Id)
+		log.Info().Msg("---------------------------------------------------------------------------------")
+		log.Info().Msgf("📢 Begin processing for partner %v", partner.Id)
+
+		// Filter in/out partners
+		if str.FilterIDs(partner.Id, request.IncludedIds, request.ExcludedIds) {
+			continue
+		}
+		start := time.Now()
+		entry := report.Entry{ArtifactType: partnerArtifactType, Id: partner.Id, Target: "git"}
+
+		downloadedPartnerPath := fmt.Sprintf("%v/%v", targetRootDir, partner.Id)
+		err = writePartner(partner, downloadedPartnerPath)
+		if err != nil {
+			request.Report.AddError(start, entry, err)
+			return err
+		}
+
+		// Compare content and update Git if required
+		gitPartnerPath := fmt.Sprintf("%v/%v", request.ArtifactsDir, partner.Id)
+		if file.Exists(fmt.Sprintf("%v/%v", gitPartnerPath, partnerFile)) {
+			// (1) If partner already exists in Git, then compare and update
+			log.Info().Msg("Comparing content from tenant against Git")
+			dirDiffer := file.DiffDirectories(downloadedPartnerPath, gitPartnerPath).HasDifferences()
+
+			if dirDiffer && request.Plan != nil {
+				request.Plan.Add(plan.Update, partnerArtifactType, partner.Id, "Git", "")
+				entry.Action = report.Updated
+			} else if dirDiffer {
+				log.Info().Msg("🏆 Changes detected and will be updated to Git")
+				entry.Action = report.Updated
+				err = file.ReplaceDir(downloadedPartnerPath, gitPartnerPath)
+				if err != nil {
+					request.Report.AddError(start, entry, err)
+					return err
+				}
+			} else {
+				log.Info().Msg("🏆 No changes detected. Update to Git not required")
+				entry.Action = report.Unchanged
+			}
+		} else if request.Plan != nil {
+			request.Plan.Add(plan.Create, partnerArtifactType, partner.Id, "Git", "")
+			entry.Action = report.Created
+		} else { // (2) If partner does not exist in Git, then add it
+			log.Info().Msgf("🏆 Partner %v does not exist, and will be added to Git", partner.Id)
+			entry.Action = report.Created
+			err = file.ReplaceDir(downloadedPartnerPath, gitPartnerPath)
+			if err != nil {
+				request.Report.AddError(start, entry, err)
+				return err
+			}
+		}
+		request.Report.Add(start, entry)
+	}
+
+	if request.Prune {
+		err = pruneGitDirectories(request, tenantIds, partnerFile, partnerArtifactType)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("🏆 Completed processing of Partner Directory")
+
+	return nil
+}
+
+type PartnerDirectoryTenantSynchroniser struct {
+	exe *httpclnt.HTTPExecuter
+}
+
+// NewPartnerDirectoryTenantSynchroniser returns an initialised PartnerDirectoryTenantSynchroniser instance.
+func NewPartnerDirectoryTenantSynchroniser(exe *httpclnt.HTTPExecuter) Syncer {
+	s := new(PartnerDirectoryTenantSynchroniser)
+	s.exe = exe
+	return s
+}
+
+func (s *PartnerDirectoryTenantSynchroniser) Exec(request Request) error {
+	// Get directory list
+	baseSourceDir := filepath.Clean(request.ArtifactsDir)
+	entries, err := os.ReadDir(baseSourceDir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	pd := api.NewPartnerDirectory(s.exe)
+	partners, err := pd.GetAll()
+	if err != nil {
+		return err
+	}
+	tenantPartners := map[string]*api.Partner{}
+	for _, partner := range partners {
+		tenantPartners[partner.Id] = partner
+	}
+
+	var gitIds []string
+	for _, entry := range entries {
+		pid := entry.Name()
+		gitPartnerDir := fmt.Sprintf("%v/%v", baseSourceDir, pid)
+		if !entry.IsDir() || !file.Exists(fmt.Sprintf("%v/%v", gitPartnerDir, partnerFile)) {
+			continue
+		}
+		gitIds = append(gitIds, pid)
+
+		log.Info().Msg("---------------------------------------------------------------------------------")
+		log.Info().Msgf("Processing directory %v", gitPartnerDir)
+
+		// Filter in/out partners
+		if str.FilterIDs(pid, request.IncludedIds, request.ExcludedIds) {
+			continue
+		}
+
+		log.Info().Msgf("📢 Begin processing for partner %v", pid)
+		start := time.Now()
+		reportEntry := report.Entry{ArtifactType: partnerArtifactType, Id: pid, Target: "tenant"}
+		gitPartner, err := readPartner(pid, gitPartnerDir)
+		if err != nil {
+			request.Report.AddError(start, reportEntry, err)
+			return err
+		}
+		tenantPartner, exists := tenantPartners[pid]
+		if !exists {
+			tenantPartner = api.NewPartner(pid)
+			reportEntry.Action = report.Created
+		} else {
+			reportEntry.Action = report.Updated
+		}
+		changes := diffPartner(tenantPartner, gitPartner, pd)
+		if len(changes) == 0 {
+			log.Info().Msg("🏆 No changes detected. Partner does not need to be updated")
+			reportEntry.Action = report.Unchanged
+			request.Report.Add(start, reportEntry)
+			continue
+		}
+		var summary []string
+		for _, change := range changes {
+			summary = append(summary, change.String())
+		}
+		reportEntry.Diff = strings.Join(summary, "\n")
+		for _, change := range changes {
+			if request.Plan != nil {
+				request.Plan.Add(change.action, change.entryType, pid, "tenant", change.id)
+				continue
+			}
+			err = change.exec()
+			if err != nil {
+				request.Report.AddError(start, reportEntry, err)
+				return err
+			}
+		}
+		if request.Plan == nil {
+			log.Info().Msgf("🏆 Partner %v synced successfully with %d change(s)", pid, len(changes))
+		}
+		request.Report.Add(start, reportEntry)
+	}
+	if len(gitIds) == 0 {
+		log.Warn().Msgf("No directory with partner contents found in %v", baseSourceDir)
+	} else if request.Prune {
+		var orphans []string
+		for _, partner := range partners {
+			if !slices.Contains(gitIds, partner.Id) && !str.FilterIDs(partner.Id, request.IncludedIds, request.ExcludedIds) {
+				orphans = append(orphans, partner.Id)
+			}
+		}
+		logOrphans(partnerArtifactType, "tenant", orphans)
+		for _, pid := range orphans {
+			start := time.Now()
+			reportEntry := report.Entry{ArtifactType: partnerArtifactType, Id: pid, Target: "tenant", Action: report.Deleted}
+			if request.Plan != nil {
+				request.Plan.Add(plan.Delete, partnerArtifactType, pid, "tenant", "")
+				request.Report.Add(start, reportEntry)
+				continue
+			}
+			log.Info().Msgf("📢 Partner %v does not exist in Git, and will be deleted from tenant", pid)
+			err = pd.DeletePartner(pid)
+			if err != nil {
+				request.Report.AddError(start, reportEntry, err)
+				return err
+			}
+			request.Report.Add(start, reportEntry)
+			log.Info().Msg("🏆 Partner deleted successfully")
+		}
+	}
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("🏆 Completed processing of Partner Directory")
+	return nil
+}
+
+// partnerChange is a change to a single entry of a partner in the tenant
+type partnerChange struct {
+	action    plan.Action
+	entryType string
+	id        string
+	exec      func() error
+}
+
+func (c partnerChange) String() string {
+	return fmt.Sprintf("%v %v %v", c.action, c.entryType, c.id)
+}
+
+// diffPartner returns the changes required to update the partner in the tenant to match the partner in Git
+func diffPartner(tenant *api.Partner, git *api.Partner, pd *api.PartnerDirectory) []partnerChange {
+	pid := git.Id
+	var changes []partnerChange
+
+	for _, id := range sortedKeys(git.StringParameters) {
+		value := git.StringParameters[id]
+		tenantValue, exists := tenant.StringParameters[id]
+		if !exists {
+			changes = append(changes, partnerChange{plan.Create, "string parameter", id, func() error { return pd.CreateStringParameter(pid, id, value) }})
+		} else if tenantValue != value {
+			changes = append(changes, partnerChange{plan.Update, "string parameter", id, func() error { return pd.UpdateStringParameter(pid, id, value) }})
+		}
+	}
+	for _, id := range sortedKeys(tenant.StringParameters) {
+		if _, exists := git.StringParameters[id]; !exists {
+			changes = append(changes, partnerChange{plan.Delete, "string parameter", id, func() error { return pd.DeleteStringParameter(pid, id) }})
+		}
+	}
+
+	for _, id := range sortedKeys(git.BinaryParameters) {
+		param := git.BinaryParameters[id]
+		tenantParam, exists := tenant.BinaryParameters[id]
+		if !exists {
+			changes = append(changes, partnerChange{plan.Create, "binary parameter", id, func() error { return pd.CreateBinaryParameter(pid, id, param) }})
+		} else if tenantParam.ContentType != param.ContentType || !bytes.Equal(tenantParam.Value, param.Value) {
+			changes = append(changes, partnerChange{plan.Update, "binary parameter", id, func() error { return pd.UpdateBinaryParameter(pid, id, param) }})
+		}
+	}
+	for _, id := range sortedKeys(tenant.BinaryParameters) {
+		if _, exists := git.BinaryParameters[id]; !exists {
+			changes = append(changes, partnerChange{plan.Delete, "binary parameter", id, func() error { return pd.DeleteBinaryParameter(pid, id) }})
+		}
+	}
+
+	for _, ap := range git.AlternativePartners {
+		if !slices.ContainsFunc(tenant.AlternativePartners, func(t *api.AlternativePartner) bool { return *t == *ap }) {
+			changes = append(changes, partnerChange{plan.Create, "alternative partner", alternativePartnerId(ap), func() error { return pd.CreateAlternativePartner(pid, ap) }})
+		}
+	}
+	for _, ap := range tenant.AlternativePartners {
+		if !slices.ContainsFunc(git.AlternativePartners, func(g *api.AlternativePartner) bool { return *g == *ap }) {
+			changes = append(changes, partnerChange{plan.Delete, "alternative partner", alternativePartnerId(ap), func() error { return pd.DeleteAlternativePartner(ap) }})
+		}
+	}
+
+	for _, user := range git.AuthorizedUsers {
+		if !slices.Contains(tenant.AuthorizedUsers, user) {
+			changes = append(changes, partnerChange{plan.Create, "authorized user", user, func() error { return pd.CreateAuthorizedUser(pid, user) }})
+		}
+	}
+	for _, user := range tenant.AuthorizedUsers {
+		if !slices.Contains(git.AuthorizedUsers, user) {
+			changes = append(changes, partnerChange{plan.Delete, "authorized user", user, func() error { return pd.DeleteAuthorizedUser(user) }})
+		}
+	}
+	return changes
+}
+
+func alternativePartnerId(ap *api.AlternativePartner) string {
+	return fmt.Sprintf("%v/%v/%v", ap.Agency, ap.Scheme, ap.Id)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// writePartner writes the partner to the directory, replacing any existing content
+func writePartner(partner *api.Partner, dir string) error {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	content, err := json.MarshalIndent(partner, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	err = os.WriteFile(fmt.Sprintf("%v/%v", dir, partnerFile), append(content, '\n'), 0644)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	if len(partner.BinaryParameters) == 0 {
+		return nil
+	}
+	binaryDir := fmt.Sprintf("%v/%v", dir, partnerBinaryDir)
+	err = os.MkdirAll(binaryDir, os.ModePerm)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	for id, param := range partner.BinaryParameters {
+		err = os.WriteFile(fmt.Sprintf("%v/%v.%v", binaryDir, id, param.ContentType), param.Value, 0644)
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
+// readPartner reads the partner from the directory
+func readPartner(pid string, dir string) (*api.Partner, error) {
+	content, err := os.ReadFile(fmt.Sprintf("%v/%v", dir, partnerFile))
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	partner := api.NewPartner(pid)
+	err = json.Unmarshal(content, partner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content in %v/%v: %w", dir, partnerFile, err)
+	}
+	// Missing sections in the file are treated as empty
+	if partner.StringParameters == nil {
+		partner.StringParameters = map[string]string{}
+	}
+	if partner.AlternativePartners == nil {
+		partner.AlternativePartners = []*api.AlternativePartner{}
+	}
+	if partner.AuthorizedUsers == nil {
+		partner.AuthorizedUsers = []string{}
+	}
+	partner.Sort()
+
+	binaryDir := fmt.Sprintf("%v/%v", dir, partnerBinaryDir)
+	if !file.Exists(binaryDir) {
+		return partner, nil
+	}
+	entries, err := os.ReadDir(binaryDir)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext == "" || ext == entry.Name() {
+			return nil, fmt.Errorf("binary parameter file %v/%v must have the content type as extension, e.g. <id>.xml", binaryDir, entry.Name())
+		}
+		value, err := os.ReadFile(fmt.Sprintf("%v/%v", binaryDir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		partner.BinaryParameters[strings.TrimSuffix(entry.Name(), ext)] = &api.BinaryParameter{ContentType: strings.TrimPrefix(ext, "."), Value: value}
+	}
+	return partner, nil
+}
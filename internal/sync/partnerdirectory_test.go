@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAndReadPartner(t *testing.T) {
+	partner := api.NewPartner("PARTNER_A")
+	partner.StringParameters["receiverURL"] = "https://a.example.com"
+	partner.BinaryParameters["mapping.v1"] = &api.BinaryParameter{ContentType: "xsl", Value: []byte("<xsl/>")}
+	partner.AlternativePartners = []*api.AlternativePartner{{Agency: "GLN", Scheme: "DOM", Id: "1"}}
+	partner.AuthorizedUsers = []string{"user1"}
+	dir := filepath.Join(t.TempDir(), "PARTNER_A")
+
+	err := writePartner(partner, dir)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "partner.json"))
+	assert.FileExists(t, filepath.Join(dir, "Binary", "mapping.v1.xsl"))
+
+	read, err := readPartner("PARTNER_A", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, partner, read)
+}
+
+func TestReadPartner_MissingSections(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "partner.json"), []byte(`{ "stringParameters": { "url": "https://example.com" } }`), 0644)
+
+	partner, err := readPartner("PARTNER_A", dir)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com", partner.StringParameters["url"])
+	assert.Empty(t, partner.AlternativePartners)
+	assert.Empty(t, partner.AuthorizedUsers)
+	assert.Empty(t, partner.BinaryParameters)
+}
+
+func TestDiffPartner(t *testing.T) {
+	tenant := api.NewPartner("PARTNER_A")
+	tenant.StringParameters = map[string]string{"unchanged": "1", "changed": "old", "removed": "x"}
+	tenant.BinaryParameters["mapping"] = &api.BinaryParameter{ContentType: "xsl", Value: []byte("<old/>")}
+	tenant.AlternativePartners = []*api.AlternativePartner{{Agency: "GLN", Scheme: "DOM", Id: "1"}}
+	tenant.AuthorizedUsers = []string{"user1"}
+
+	git := api.NewPartner("PARTNER_A")
+	git.StringParameters = map[string]string{"unchanged": "1", "changed": "new", "added": "y"}
+	git.BinaryParameters["mapping"] = &api.BinaryParameter{ContentType: "xsl", Value: []byte("<new/>")}
+	git.AlternativePartners = []*api.AlternativePartner{{Agency: "GLN", Scheme: "DOM", Id: "1"}, {Agency: "DUNS", Scheme: "DOM", Id: "2"}}
+	git.AuthorizedUsers = []string{"user2"}
+
+	changes := diffPartner(tenant, git, nil)
+
+	var summary []string
+	for _, change := range changes {
+		summary = append(summary, change.String())
+	}
+	assert.Equal(t, []string{
+		"create string parameter added",
+		"update string parameter changed",
+		"delete string parameter removed",
+		"update binary parameter mapping",
+		"create alternative partner DUNS/DOM/2",
+		"create authorized user user2",
+		"delete authorized user user1",
+	}, summary)
+	assert.Equal(t, plan.Create, changes[0].action)
+
+	assert.Empty(t, diffPartner(git, git, nil))
+}
@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/go-errors/errors"
 	"github.com/rs/zerolog/log"
@@ -24,6 +28,9 @@ type Request struct {
 	IncludedIds  []string
 	ExcludedIds  []string
 	PackageFile  string
+	Prune        bool           // Delete artifacts that only exist on the target side
+	Plan         *plan.Plan     // Record changes in the plan instead of executing them (dry run)
+	Report       *report.Report // Record the result of each processed artifact
 }
 
 func NewSyncer(target string, functionType string, exe *httpclnt.HTTPExecuter) Syncer {
@@ -46,6 +53,15 @@ func NewSyncer(target string, functionType string, exe *httpclnt.HTTPExecuter) S
 		default:
 			return nil
 		}
+	case "PartnerDirectory":
+		switch target {
+		case "git":
+			return NewPartnerDirectoryGitSynchroniser(exe)
+		case "tenant":
+			return NewPartnerDirectoryTenantSynchroniser(exe)
+		default:
+			return nil
+		}
 	case "CPIPackage":
 		switch target {
 		case "tenant":
@@ -111,9 +127,11 @@ func (s *APIProxyGitSynchroniser) Exec(request Request) error {
 		if file.Exists(fmt.Sprintf("%v/manifest.json", gitArtifactPath)) {
 			// (1) If artifact already exists in Git, then compare and update
 			log.Info().Msg("Comparing content from tenant against Git")
-			dirDiffer := file.DiffDirectories(downloadedArtifactPath, gitArtifactPath)
+			dirDiffer := file.DiffDirectories(downloadedArtifactPath, gitArtifactPath).HasDifferences()
 
-			if dirDiffer {
+			if dirDiffer && request.Plan != nil {
+				request.Plan.Add(plan.Update, "APIProxy", artifact.Name, "Git", "")
+			} else if dirDiffer {
 				log.Info().Msg("🏆 Changes detected and will be updated to Git")
 				// Update the changes into the Git directory
 				err := file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
@@ -123,6 +141,8 @@ func (s *APIProxyGitSynchroniser) Exec(request Request) error {
 			} else {
 				log.Info().Msg("🏆 No changes detected. Update to Git not required")
 			}
+		} else if request.Plan != nil {
+			request.Plan.Add(plan.Create, "APIProxy", artifact.Name, "Git", "")
 		} else { // (2) If artifact does not exist in Git, then add it
 			log.Info().Msgf("🏆 APIProxy %v does not exist, and will be added to Git", artifact.Name)
 			err = file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
@@ -132,6 +152,17 @@ func (s *APIProxyGitSynchroniser) Exec(request Request) error {
 		}
 	}
 
+	if request.Prune {
+		var tenantNames []string
+		for _, artifact := range artifacts {
+			tenantNames = append(tenantNames, artifact.Name)
+		}
+		err = pruneGitDirectories(request, tenantNames, "manifest.json", "APIProxy")
+		if err != nil {
+			return err
+		}
+	}
+
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msgf("🏆 Completed processing of APIProxies")
 
@@ -171,12 +202,12 @@ func (s *APIProxyTenantSynchroniser) Exec(request Request) error {
 		return errors.Wrap(err, 0)
 	}
 
-	artifactDirFound := false
+	var gitArtifactIds []string
 	for _, entry := range entries {
 		artifactId := entry.Name()
 		manifestPath := fmt.Sprintf("%v/%v/manifest.json", baseSourceDir, artifactId)
 		if entry.IsDir() && file.Exists(manifestPath) {
-			artifactDirFound = true
+			gitArtifactIds = append(gitArtifactIds, artifactId)
 			gitArtifactDir := fmt.Sprintf("%v/%v", baseSourceDir, artifactId)
 
 			log.Info().Msg("---------------------------------------------------------------------------------")
@@ -192,7 +223,9 @@ func (s *APIProxyTenantSynchroniser) Exec(request Request) error {
 			if err != nil {
 				return err
 			}
-			if !proxyExists {
+			if !proxyExists && request.Plan != nil {
+				request.Plan.Add(plan.Create, "APIProxy", artifactId, "tenant", "")
+			} else if !proxyExists {
 				log.Info().Msgf("APIProxy %v will be created", artifactId)
 
 				err = proxy.Upload(gitArtifactDir, uploadWorkDir)
@@ -211,8 +244,10 @@ func (s *APIProxyTenantSynchroniser) Exec(request Request) error {
 
 				log.Info().Msg("Comparing content from tenant against Git")
 				downloadArtifactDir := fmt.Sprintf("%v/%v", downloadWorkDir, artifactId)
-				dirDiffer := file.DiffDirectories(downloadArtifactDir, gitArtifactDir)
-				if dirDiffer {
+				dirDiffer := file.DiffDirectories(downloadArtifactDir, gitArtifactDir).HasDifferences()
+				if dirDiffer && request.Plan != nil {
+					request.Plan.Add(plan.Update, "APIProxy", artifactId, "tenant", "")
+				} else if dirDiffer {
 					log.Info().Msg("Changes found in APIProxy. APIProxy will be updated in tenant")
 
 					err = proxy.Upload(gitArtifactDir, uploadWorkDir)
@@ -226,8 +261,32 @@ func (s *APIProxyTenantSynchroniser) Exec(request Request) error {
 			}
 		}
 	}
-	if !artifactDirFound {
+	if len(gitArtifactIds) == 0 {
 		log.Warn().Msgf("No directory with APIProxy contents found in %v", baseSourceDir)
+	} else if request.Prune {
+		artifacts, err := proxy.List()
+		if err != nil {
+			return err
+		}
+		var orphans []string
+		for _, artifact := range artifacts {
+			if !slices.Contains(gitArtifactIds, artifact.Name) && !str.FilterIDs(artifact.Name, request.IncludedIds, request.ExcludedIds) {
+				orphans = append(orphans, artifact.Name)
+			}
+		}
+		logOrphans("APIProxy", "tenant", orphans)
+		for _, id := range orphans {
+			if request.Plan != nil {
+				request.Plan.Add(plan.Delete, "APIProxy", id, "tenant", "")
+				continue
+			}
+			log.Info().Msgf("📢 APIProxy %v does not exist in Git, and will be deleted from tenant", id)
+			err = proxy.Delete(id)
+			if err != nil {
+				return err
+			}
+			log.Info().Msg("🏆 APIProxy deleted successfully")
+		}
 	}
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msgf("🏆 Completed processing of APIProxies")
@@ -246,6 +305,7 @@ func NewCPIPackageTenantSynchroniser(exe *httpclnt.HTTPExecuter) Syncer {
 }
 
 func (s *CPIPackageTenantSynchroniser) Exec(request Request) error {
+	start := time.Now()
 	var packageFile string
 	if request.PackageFile != "" {
 		packageFile = request.PackageFile
@@ -262,25 +322,46 @@ func (s *CPIPackageTenantSynchroniser) Exec(request Request) error {
 	ip := api.NewIntegrationPackage(s.exe)
 
 	packageId := packageDetails.Root.Id
-	_, _, exists, err := ip.Get(packageId)
+	entry := report.Entry{ArtifactType: "package", Id: packageId, Target: "tenant", VersionAfter: packageDetails.Root.Version}
+	tenantPackage, _, exists, err := ip.Get(packageId)
 	if err != nil {
+		request.Report.AddError(start, entry, err)
 		return err
 	}
+	if exists {
+		entry.VersionBefore = tenantPackage.Root.Version
+	}
+	if request.Plan != nil {
+		if !exists {
+			request.Plan.Add(plan.Create, "package", packageId, "tenant", "")
+			entry.Action = report.Created
+		} else {
+			request.Plan.Add(plan.Update, "package", packageId, "tenant", "")
+			entry.Action = report.Updated
+		}
+		request.Report.Add(start, entry)
+		return nil
+	}
 	if !exists {
 		log.Info().Msgf("Package %v does not exist", packageId)
+		entry.Action = report.Created
 		err = ip.Create(packageDetails)
 		if err != nil {
+			request.Report.AddError(start, entry, err)
 			return err
 		}
 		log.Info().Msgf("Package %v created", packageId)
 	} else {
 		// Update integration package
+		entry.Action = report.Updated
 		err = ip.Update(packageDetails)
 		if err != nil {
+			request.Report.AddError(start, entry, err)
 			return err
 		}
 		log.Info().Msgf("Package %v updated", packageId)
 	}
+	request.Report.Add(start, entry)
 	return nil
 }
 
@@ -334,9 +415,11 @@ func (s *APIProductGitSynchroniser) Exec(request Request) error {
 		if file.Exists(gitArtifactPath) {
 			// (1) If artifact already exists in Git, then compare and update
 			log.Info().Msg("Comparing content from tenant against Git")
-			fileDiffer := file.DiffFile(downloadedArtifactPath, gitArtifactPath)
+			fileDiffer := file.DiffFile(downloadedArtifactPath, gitArtifactPath).HasDifferences()
 
-			if fileDiffer {
+			if fileDiffer && request.Plan != nil {
+				request.Plan.Add(plan.Update, "APIProduct", artifact.Name, "Git", "")
+			} else if fileDiffer {
 				log.Info().Msg("🏆 Changes detected and will be updated to Git")
 				// Update the changes into the Git
 				err := file.CopyFile(downloadedArtifactPath, gitArtifactPath)
@@ -346,6 +429,8 @@ func (s *APIProductGitSynchroniser) Exec(request Request) error {
 			} else {
 				log.Info().Msg("🏆 No changes detected. Update to Git not required")
 			}
+		} else if request.Plan != nil {
+			request.Plan.Add(plan.Create, "APIProduct", artifact.Name, "Git", "")
 		} else { // (2) If artifact does not exist in Git, then add it
 			log.Info().Msgf("🏆 APIProduct %v does not exist, and will be added to Git", artifact.Name)
 			err = file.CopyFile(downloadedArtifactPath, gitArtifactPath)
@@ -355,6 +440,17 @@ func (s *APIProductGitSynchroniser) Exec(request Request) error {
 		}
 	}
 
+	if request.Prune {
+		var tenantNames []string
+		for _, artifact := range artifacts {
+			tenantNames = append(tenantNames, artifact.Name)
+		}
+		err = pruneGitFiles(request, tenantNames, ".json", "APIProduct")
+		if err != nil {
+			return err
+		}
+	}
+
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msgf("🏆 Completed processing of APIProducts")
 
@@ -394,11 +490,10 @@ func (s *APIProductTenantSynchroniser) Exec(request Request) error {
 		return errors.Wrap(err, 0)
 	}
 
-	artifactFileFound := false
+	var gitArtifactIds []string
 	for _, entry := range entries {
 		artifactFileName := entry.Name()
 		if !entry.IsDir() && strings.Contains(artifactFileName, ".json") {
-			artifactFileFound = true
 			gitArtifactPath := fmt.Sprintf("%v/%v", baseSourceDir, artifactFileName)
 
 			log.Info().Msg("---------------------------------------------------------------------------------")
@@ -406,6 +501,7 @@ func (s *APIProductTenantSynchroniser) Exec(request Request) error {
 
 			// Strip .json from the file name
 			artifactId := strings.TrimSuffix(artifactFileName, ".json")
+			gitArtifactIds = append(gitArtifactIds, artifactId)
 
 			// Filter in/out artifacts
 			if str.FilterIDs(artifactId, request.IncludedIds, request.ExcludedIds) {
@@ -417,7 +513,9 @@ func (s *APIProductTenantSynchroniser) Exec(request Request) error {
 			if err != nil {
 				return err
 			}
-			if !productExists {
+			if !productExists && request.Plan != nil {
+				request.Plan.Add(plan.Create, "APIProduct", artifactId, "tenant", "")
+			} else if !productExists {
 				log.Info().Msgf("APIProduct %v will be created", artifactId)
 
 				err = product.Upload(gitArtifactPath, uploadWorkDir)
@@ -431,10 +529,100 @@ func (s *APIProductTenantSynchroniser) Exec(request Request) error {
 			}
 		}
 	}
-	if !artifactFileFound {
+	if len(gitArtifactIds) == 0 {
 		log.Warn().Msgf("No directory with APIProduct contents found in %v", baseSourceDir)
+	} else if request.Prune {
+		artifacts, err := product.List()
+		if err != nil {
+			return err
+		}
+		var orphans []string
+		for _, artifact := range artifacts {
+			if !slices.Contains(gitArtifactIds, artifact.Name) && !str.FilterIDs(artifact.Name, request.IncludedIds, request.ExcludedIds) {
+				orphans = append(orphans, artifact.Name)
+			}
+		}
+		logOrphans("APIProduct", "tenant", orphans)
+		for _, id := range orphans {
+			if request.Plan != nil {
+				request.Plan.Add(plan.Delete, "APIProduct", id, "tenant", "")
+				continue
+			}
+			log.Info().Msgf("📢 APIProduct %v does not exist in Git, and will be deleted from tenant", id)
+			err = product.Delete(id)
+			if err != nil {
+				return err
+			}
+			log.Info().Msg("🏆 APIProduct deleted successfully")
+		}
 	}
 	log.Info().Msg("---------------------------------------------------------------------------------")
 	log.Info().Msgf("🏆 Completed processing of APIProducts")
 	return nil
 }
+
+// pruneGitDirectories removes artifact directories (identified by the marker file) that do not exist in the tenant
+func pruneGitDirectories(request Request, tenantIds []string, markerFile string, artifactType string) error {
+	entries, err := os.ReadDir(request.ArtifactsDir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	var orphans []string
+	for _, entry := range entries {
+		id := entry.Name()
+		if entry.IsDir() && file.Exists(fmt.Sprintf("%v/%v/%v", request.ArtifactsDir, id, markerFile)) &&
+			!slices.Contains(tenantIds, id) && !str.FilterIDs(id, request.IncludedIds, request.ExcludedIds) {
+			orphans = append(orphans, id)
+		}
+	}
+	logOrphans(artifactType, "Git", orphans)
+	for _, id := range orphans {
+		if request.Plan != nil {
+			request.Plan.Add(plan.Delete, artifactType, id, "Git", "")
+			continue
+		}
+		log.Info().Msgf("🏆 %v %v does not exist in tenant, and will be removed from Git", artifactType, id)
+		err = os.RemoveAll(fmt.Sprintf("%v/%v", request.ArtifactsDir, id))
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
+// pruneGitFiles removes artifact files (with the given extension) that do not exist in the tenant
+func pruneGitFiles(request Request, tenantIds []string, extension string, artifactType string) error {
+	entries, err := os.ReadDir(request.ArtifactsDir)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	var orphans []string
+	for _, entry := range entries {
+		id, found := strings.CutSuffix(entry.Name(), extension)
+		if !entry.IsDir() && found && !slices.Contains(tenantIds, id) && !str.FilterIDs(id, request.IncludedIds, request.ExcludedIds) {
+			orphans = append(orphans, id)
+		}
+	}
+	logOrphans(artifactType, "Git", orphans)
+	for _, id := range orphans {
+		if request.Plan != nil {
+			request.Plan.Add(plan.Delete, artifactType, id, "Git", "")
+			continue
+		}
+		log.Info().Msgf("🏆 %v %v does not exist in tenant, and will be removed from Git", artifactType, id)
+		err = os.Remove(fmt.Sprintf("%v/%v%v", request.ArtifactsDir, id, extension))
+		if err != nil {
+			return errors.Wrap(err, 0)
+		}
+	}
+	return nil
+}
+
+func logOrphans(artifactType string, target string, ids []string) {
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	if len(ids) == 0 {
+		log.Info().Msgf("📢 No %v found for pruning from %v", artifactType, target)
+		return
+	}
+	log.Info().Msgf("📢 Pruning %d %v(s) from %v: %v", len(ids), artifactType, target, strings.Join(ids, ", "))
+}
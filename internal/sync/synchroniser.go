@@ -9,10 +9,14 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/engswee/flashpipe/internal/api"
 	"github.com/engswee/flashpipe/internal/file"
 	"github.com/engswee/flashpipe/internal/httpclnt"
+	"github.com/engswee/flashpipe/internal/plan"
+	"github.com/engswee/flashpipe/internal/report"
+	"github.com/engswee/flashpipe/internal/schedule"
 	"github.com/engswee/flashpipe/internal/str"
 	"github.com/go-errors/errors"
 	"github.com/magiconair/properties"
@@ -20,8 +24,13 @@ import (
 )
 
 type Synchroniser struct {
-	exe *httpclnt.HTTPExecuter
-	ip  *api.IntegrationPackage
+	exe                *httpclnt.HTTPExecuter
+	ip                 *api.IntegrationPackage
+	environment        string
+	prune              bool
+	valueMappingFormat string
+	plan               *plan.Plan
+	report             *report.Report
 }
 
 func New(exe *httpclnt.HTTPExecuter) *Synchroniser {
@@ -31,6 +40,32 @@ func New(exe *httpclnt.HTTPExecuter) *Synchroniser {
 	return s
 }
 
+// SetEnvironment sets the environment (e.g. QA, PRD) whose parameter overlays are applied when syncing artifacts to the tenant.
+func (s *Synchroniser) SetEnvironment(environment string) {
+	s.environment = environment
+}
+
+// SetPrune sets whether artifacts that only exist on the target side of the sync are deleted.
+func (s *Synchroniser) SetPrune(prune bool) {
+	s.prune = prune
+}
+
+// SetValueMappingFormat sets the format (xml, csv or yaml) of value mappings synced to Git. If it is not set, value
+// mappings keep the format they already have in Git.
+func (s *Synchroniser) SetValueMappingFormat(format string) {
+	s.valueMappingFormat = format
+}
+
+// SetDryRun sets the plan which records changes instead of executing them. Read and comparison calls are still made.
+func (s *Synchroniser) SetDryRun(p *plan.Plan) {
+	s.plan = p
+}
+
+// SetReport sets the report which records the result of each processed artifact.
+func (s *Synchroniser) SetReport(r *report.Report) {
+	s.report = r
+}
+
 func (s *Synchroniser) PackageToGit(packageDataFromTenant *api.PackageSingleData, packageId string, workDir string, artifactsDir string) error {
 	// Create temp directory in working dir
 	err := os.MkdirAll(workDir+"/from_tenant", os.ModePerm)
@@ -63,14 +98,20 @@ func (s *Synchroniser) PackageToGit(packageDataFromTenant *api.PackageSingleData
 			return err
 		}
 		if packageContentDiffer(packageDataFromTenant, packageDataFromGit) {
-			log.Info().Msgf("🏆 Changes to package %v detected and will be updated to Git", packageId)
-			err = file.CopyFile(tenantFile, gitSourceFile)
-			if err != nil {
-				return err
+			if s.plan != nil {
+				s.plan.Add(plan.Update, "package", packageId, "Git", "")
+			} else {
+				log.Info().Msgf("🏆 Changes to package %v detected and will be updated to Git", packageId)
+				err = file.CopyFile(tenantFile, gitSourceFile)
+				if err != nil {
+					return err
+				}
 			}
 		} else {
 			log.Info().Msgf("🏆 No changes to package %v detected. Update to Git not required", packageId)
 		}
+	} else if s.plan != nil {
+		s.plan.Add(plan.Create, "package", packageId, "Git", "")
 	} else {
 		log.Info().Msgf("🏆 Saving new file for package %v to Git", packageId)
 		err = file.CopyFile(tenantFile, gitSourceFile)
@@ -123,91 +164,139 @@ func (s *Synchroniser) ArtifactsToGit(packageId string, workDir string, artifact
 
 	// Process through the artifacts
 	for _, artifact := range filtered {
-		log.Info().Msg("---------------------------------------------------------------------------------")
-		log.Info().Msgf("📢 Begin processing for artifact %v", artifact.Id)
-		// Check if artifact is in draft version
-		if artifact.IsDraft {
-			switch draftHandling {
-			case "SKIP":
-				log.Warn().Msgf("Artifact %v is in draft version, and will be skipped", artifact.Id)
-				continue
-			case "ADD":
-				log.Info().Msgf("Artifact %v is in draft version, and will be added", artifact.Id)
-			case "ERROR":
-				return fmt.Errorf("Artifact %v is in draft version. Save Version in Web UI first!", artifact.Id)
-			}
+		err = s.artifactToGit(artifact, workDir, artifactsDir, draftHandling, dirNamingType, scriptCollectionMap)
+		if err != nil {
+			return err
 		}
-		// Download artifact content
-		dt := api.NewDesigntimeArtifact(artifact.ArtifactType, s.exe)
-		targetDownloadFile := fmt.Sprintf("%v/download/%v.zip", workDir, artifact.Id)
-		err = dt.Download(targetDownloadFile, artifact.Id)
+	}
+
+	if s.prune {
+		err = s.pruneGitArtifacts(artifactsDir, artifacts, dirNamingType, includedIds, excludedIds)
 		if err != nil {
 			return err
 		}
+	}
 
-		// TODO - override directory name using key value pair - to cater for syncing artifact from different environment
-		var directoryName string
-		if dirNamingType == "NAME" {
-			directoryName = artifact.Name
+	// Clean up working directory
+	err = os.RemoveAll(workDir + "/download")
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("🏆 Completed processing of artifacts in integration package %v", packageId)
+	return nil
+}
+
+func (s *Synchroniser) artifactToGit(artifact *api.ArtifactDetails, workDir string, artifactsDir string, draftHandling string, dirNamingType string, scriptCollectionMap []string) (err error) {
+	start := time.Now()
+	entry := report.Entry{ArtifactType: artifact.ArtifactType, Id: artifact.Id, Target: "Git", VersionAfter: artifact.Version}
+	defer func() {
+		if err != nil {
+			s.report.AddError(start, entry, err)
 		} else {
-			directoryName = artifact.Id
+			s.report.Add(start, entry)
 		}
-		// Unzip artifact contents
-		log.Debug().Msgf("Target artifact directory name - %v", directoryName)
-		downloadedArtifactPath := fmt.Sprintf("%v/download/%v", workDir, directoryName)
-		err = file.UnzipSource(targetDownloadFile, downloadedArtifactPath)
-		if err != nil {
-			return err
+	}()
+
+	log.Info().Msg("---------------------------------------------------------------------------------")
+	log.Info().Msgf("📢 Begin processing for artifact %v", artifact.Id)
+	// Check if artifact is in draft version
+	if artifact.IsDraft {
+		switch draftHandling {
+		case "SKIP":
+			log.Warn().Msgf("Artifact %v is in draft version, and will be skipped", artifact.Id)
+			entry.Action = report.Skipped
+			entry.Message = "draft version"
+			return nil
+		case "ADD":
+			log.Info().Msgf("Artifact %v is in draft version, and will be added", artifact.Id)
+		case "ERROR":
+			return fmt.Errorf("Artifact %v is in draft version. Save Version in Web UI first!", artifact.Id)
 		}
-		log.Info().Msgf("Downloaded artifact unzipped to %v", downloadedArtifactPath)
+	}
+	// Download artifact content
+	dt := api.NewDesigntimeArtifact(artifact.ArtifactType, s.exe)
+	targetDownloadFile := fmt.Sprintf("%v/download/%v.zip", workDir, artifact.Id)
+	err = dt.Download(targetDownloadFile, artifact.Id)
+	if err != nil {
+		return err
+	}
 
-		gitArtifactPath := fmt.Sprintf("%v/%v", artifactsDir, directoryName)
-		if file.Exists(fmt.Sprintf("%v/META-INF/MANIFEST.MF", gitArtifactPath)) {
-			// (1) If artifact already exists in Git, then compare and update
-			log.Info().Msg("Comparing content from tenant against Git")
+	// TODO - override directory name using key value pair - to cater for syncing artifact from different environment
+	var directoryName string
+	if dirNamingType == "NAME" {
+		directoryName = artifact.Name
+	} else {
+		directoryName = artifact.Id
+	}
+	// Unzip artifact contents
+	log.Debug().Msgf("Target artifact directory name - %v", directoryName)
+	downloadedArtifactPath := fmt.Sprintf("%v/download/%v", workDir, directoryName)
+	err = file.UnzipSource(targetDownloadFile, downloadedArtifactPath)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Downloaded artifact unzipped to %v", downloadedArtifactPath)
 
-			// Diff artifact contents
-			dirDiffer, err := dt.CompareContent(downloadedArtifactPath, gitArtifactPath, scriptCollectionMap, "git")
-			if err != nil {
-				return err
-			}
+	gitArtifactPath := fmt.Sprintf("%v/%v", artifactsDir, directoryName)
+	if artifact.ArtifactType == "ValueMapping" {
+		err = s.renderValueMappings(downloadedArtifactPath, gitArtifactPath)
+		if err != nil {
+			return err
+		}
+	}
+	if file.Exists(fmt.Sprintf("%v/META-INF/MANIFEST.MF", gitArtifactPath)) {
+		// (1) If artifact already exists in Git, then compare and update
+		log.Info().Msg("Comparing content from tenant against Git")
+		entry.VersionBefore = manifestVersion(gitArtifactPath)
 
-			if dirDiffer {
-				log.Info().Msg("🏆 Changes detected and will be updated to Git")
-				// Update the changes into the Git directory
-				err = dt.CopyContent(downloadedArtifactPath, gitArtifactPath)
-				if err != nil {
-					return err
-				}
-			} else {
-				log.Info().Msg("🏆 No changes detected. Update to Git not required")
-			}
+		// Diff artifact contents
+		diffResult, err := dt.CompareContent(downloadedArtifactPath, gitArtifactPath, scriptCollectionMap, "git")
+		if err != nil {
+			return err
+		}
+		if artifact.ArtifactType == "ValueMapping" {
+			s.diffValueMappingFormat(diffResult, gitArtifactPath)
+		}
+		dirDiffer := diffResult.HasDifferences()
 
-		} else { // (2) If artifact does not exist in Git, then add it
-			log.Info().Msgf("🏆 Artifact %v does not exist, and will be added to Git", artifact.Id)
-			// Update the script collection in IFlow BPMN2 XML before syncing to Git
-			if artifact.ArtifactType == "Integration" {
-				err = file.UpdateBPMN(downloadedArtifactPath, scriptCollectionMap)
-				if err != nil {
-					return err
-				}
-			}
-			err = file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
+		if dirDiffer {
+			entry.Action = report.Updated
+			entry.Diff = diffResult.Summary()
+		} else {
+			entry.Action = report.Unchanged
+		}
+		if dirDiffer && s.plan != nil {
+			s.plan.Add(plan.Update, artifact.ArtifactType, artifact.Id, "Git", "")
+		} else if dirDiffer {
+			log.Info().Msg("🏆 Changes detected and will be updated to Git")
+			// Update the changes into the Git directory
+			err = dt.CopyContent(downloadedArtifactPath, gitArtifactPath)
 			if err != nil {
 				return err
 			}
+		} else {
+			log.Info().Msg("🏆 No changes detected. Update to Git not required")
 		}
+		return nil
 	}
 
-	// Clean up working directory
-	err = os.RemoveAll(workDir + "/download")
-	if err != nil {
-		return errors.Wrap(err, 0)
+	// (2) If artifact does not exist in Git, then add it
+	entry.Action = report.Created
+	if s.plan != nil {
+		s.plan.Add(plan.Create, artifact.ArtifactType, artifact.Id, "Git", "")
+		return nil
 	}
-
-	log.Info().Msg("---------------------------------------------------------------------------------")
-	log.Info().Msgf("🏆 Completed processing of artifacts in integration package %v", packageId)
-	return nil
+	log.Info().Msgf("🏆 Artifact %v does not exist, and will be added to Git", artifact.Id)
+	// Update the script collection in IFlow BPMN2 XML before syncing to Git
+	if artifact.ArtifactType == "Integration" {
+		err = file.UpdateBPMN(downloadedArtifactPath, scriptCollectionMap)
+		if err != nil {
+			return err
+		}
+	}
+	return file.ReplaceDir(downloadedArtifactPath, gitArtifactPath)
 }
 
 func filterArtifacts(artifacts []*api.ArtifactDetails, includedIds []string, excludedIds []string) ([]*api.ArtifactDetails, error) {
@@ -285,25 +374,22 @@ func (s *Synchroniser) ArtifactsToTenant(packageId string, workDir string, artif
 		return errors.Wrap(err, 0)
 	}
 
-	artifactDirFound := false
+	var gitArtifactIds []string
 	for _, entry := range entries {
 		manifestPath := fmt.Sprintf("%v/%v/META-INF/MANIFEST.MF", baseSourceDir, entry.Name())
 		if entry.IsDir() && file.Exists(manifestPath) {
-			artifactDirFound = true
 			artifactDir := fmt.Sprintf("%v/%v", baseSourceDir, entry.Name())
 			log.Info().Msg("---------------------------------------------------------------------------------")
 			log.Info().Msgf("Processing directory %v", artifactDir)
-			paramFile := fmt.Sprintf("%v/src/main/resouces/parameters/prop", artifactDir)
+			paramFile := fmt.Sprintf("%v/src/main/resources/parameters.prop", artifactDir)
 
 			headers, err := GetManifestHeaders(manifestPath)
 			if err != nil {
 				return err
 			}
 
-			artifactId := headers.Get("Bundle-SymbolicName")
-			// remove spaces then remove ;singleton:=true
-			artifactId = strings.ReplaceAll(artifactId, " ", "")
-			artifactId = strings.ReplaceAll(artifactId, ";singleton:=true", "")
+			artifactId := getArtifactId(headers)
+			gitArtifactIds = append(gitArtifactIds, artifactId)
 
 			// Filter in/out artifacts
 			if len(includedIds) > 0 {
@@ -328,18 +414,183 @@ func (s *Synchroniser) ArtifactsToTenant(packageId string, workDir string, artif
 			}
 
 			log.Info().Msgf("📢 Begin processing for artifact %v", artifactId)
-			err = s.SingleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, paramFile, nil)
+			err = s.singleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, paramFile, s.packageParametersFile(baseSourceDir), nil)
 			if err != nil {
 				return err
 			}
 		}
 	}
-	if !artifactDirFound {
+	if len(gitArtifactIds) == 0 {
 		log.Warn().Msgf("No directory with artifact contents found in %v", baseSourceDir)
+		if s.prune {
+			log.Warn().Msgf("Skipping pruning of artifacts in integration package %v", packageId)
+		}
+		return nil
+	}
+	if s.prune {
+		return s.pruneTenantArtifacts(packageId, gitArtifactIds, includedIds, excludedIds)
+	}
+	return nil
+}
+
+func getArtifactId(headers textproto.MIMEHeader) string {
+	artifactId := headers.Get("Bundle-SymbolicName")
+	// remove spaces then remove ;singleton:=true
+	artifactId = strings.ReplaceAll(artifactId, " ", "")
+	return strings.ReplaceAll(artifactId, ";singleton:=true", "")
+}
+
+func (s *Synchroniser) pruneGitArtifacts(artifactsDir string, artifacts []*api.ArtifactDetails, dirNamingType string, includedIds []string, excludedIds []string) error {
+	tenantDirs := map[string]bool{}
+	for _, artifact := range artifacts {
+		if dirNamingType == "NAME" {
+			tenantDirs[artifact.Name] = true
+		} else {
+			tenantDirs[artifact.Id] = true
+		}
+	}
+
+	entries, err := os.ReadDir(artifactsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, 0)
+	}
+	var orphans []string
+	for _, entry := range entries {
+		manifestPath := fmt.Sprintf("%v/%v/META-INF/MANIFEST.MF", artifactsDir, entry.Name())
+		if !entry.IsDir() || tenantDirs[entry.Name()] || !file.Exists(manifestPath) {
+			continue
+		}
+		headers, err := GetManifestHeaders(manifestPath)
+		if err != nil {
+			return err
+		}
+		if str.FilterIDs(getArtifactId(headers), includedIds, excludedIds) {
+			continue
+		}
+		orphans = append(orphans, entry.Name())
+	}
+
+	logOrphans("artifact", "Git", orphans)
+	for _, directoryName := range orphans {
+		start := time.Now()
+		entry := report.Entry{ArtifactType: "artifact directory", Id: directoryName, Target: "Git", Action: report.Deleted}
+		if s.plan != nil {
+			s.plan.Add(plan.Delete, "artifact directory", directoryName, "Git", "")
+			s.report.Add(start, entry)
+			continue
+		}
+		log.Info().Msgf("🏆 Artifact directory %v does not exist in tenant, and will be removed from Git", directoryName)
+		err = os.RemoveAll(fmt.Sprintf("%v/%v", artifactsDir, directoryName))
+		if err != nil {
+			s.report.AddError(start, entry, err)
+			return errors.Wrap(err, 0)
+		}
+		s.report.Add(start, entry)
+	}
+	return nil
+}
+
+func (s *Synchroniser) pruneTenantArtifacts(packageId string, gitArtifactIds []string, includedIds []string, excludedIds []string) error {
+	artifacts, err := s.ip.GetAllArtifacts(packageId)
+	if err != nil {
+		return err
+	}
+	var orphans []*api.ArtifactDetails
+	var orphanIds []string
+	for _, artifact := range artifacts {
+		if slices.Contains(gitArtifactIds, artifact.Id) || str.FilterIDs(artifact.Id, includedIds, excludedIds) {
+			continue
+		}
+		orphans = append(orphans, artifact)
+		orphanIds = append(orphanIds, artifact.Id)
+	}
+
+	logOrphans("artifact", "tenant", orphanIds)
+	for _, artifact := range orphans {
+		log.Info().Msg("---------------------------------------------------------------------------------")
+		log.Info().Msgf("📢 Artifact %v does not exist in Git, and will be deleted from tenant", artifact.Id)
+		start := time.Now()
+		entry := report.Entry{ArtifactType: artifact.ArtifactType, Id: artifact.Id, Target: "tenant", Action: report.Deleted, VersionBefore: artifact.Version}
+		err = s.deleteTenantArtifact(artifact)
+		if err != nil {
+			s.report.AddError(start, entry, err)
+			return err
+		}
+		s.report.Add(start, entry)
 	}
 	return nil
 }
 
+func (s *Synchroniser) deleteTenantArtifact(artifact *api.ArtifactDetails) error {
+	err := s.undeploy(artifact.Id)
+	if err != nil {
+		return err
+	}
+	if s.plan != nil {
+		s.plan.Add(plan.Delete, artifact.ArtifactType, artifact.Id, "tenant", "")
+		return nil
+	}
+	err = api.NewDesigntimeArtifact(artifact.ArtifactType, s.exe).Delete(artifact.Id)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("🏆 Designtime artifact %v deleted successfully", artifact.Id)
+	return nil
+}
+
+// DeletePackage undeploys all runtime artifacts of the integration package before deleting it from the tenant.
+func (s *Synchroniser) DeletePackage(packageId string) (err error) {
+	start := time.Now()
+	entry := report.Entry{ArtifactType: "package", Id: packageId, Target: "tenant", Action: report.Deleted}
+	defer func() {
+		if err != nil {
+			s.report.AddError(start, entry, err)
+		} else {
+			s.report.Add(start, entry)
+		}
+	}()
+
+	artifacts, err := s.ip.GetAllArtifacts(packageId)
+	if err != nil {
+		return err
+	}
+	for _, artifact := range artifacts {
+		err = s.undeploy(artifact.Id)
+		if err != nil {
+			return err
+		}
+	}
+	if s.plan != nil {
+		s.plan.Add(plan.Delete, "package", packageId, "tenant", "")
+		return nil
+	}
+	err = s.ip.Delete(packageId)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("🏆 Integration package %v deleted successfully", packageId)
+	return nil
+}
+
+func (s *Synchroniser) undeploy(artifactId string) error {
+	r := api.NewRuntime(s.exe)
+	version, _, err := r.Get(artifactId)
+	if err != nil {
+		return err
+	}
+	if version == "NOT_DEPLOYED" {
+		return nil
+	}
+	if s.plan != nil {
+		s.plan.Add(plan.Undeploy, "runtime artifact", artifactId, "tenant", fmt.Sprintf("version %v", version))
+		return nil
+	}
+	return r.UnDeploy(artifactId)
+}
+
 func GetManifestHeaders(manifestPath string) (textproto.MIMEHeader, error) {
 	manifestFile, err := os.Open(manifestPath)
 	if err != nil {
@@ -356,15 +607,37 @@ func GetManifestHeaders(manifestPath string) (textproto.MIMEHeader, error) {
 }
 
 func (s *Synchroniser) SingleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile string, scriptMap []string) error {
+	return s.singleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile, "", scriptMap)
+}
+
+func (s *Synchroniser) singleArtifactToTenant(artifactId, artifactName, artifactType, packageId, artifactDir, workDir, parametersFile, packageParametersFile string, scriptMap []string) (err error) {
+	start := time.Now()
+	entry := report.Entry{ArtifactType: artifactType, Id: artifactId, Target: "tenant"}
+	defer func() {
+		if err != nil {
+			s.report.AddError(start, entry, err)
+		} else {
+			s.report.Add(start, entry)
+		}
+	}()
+
 	dt := api.NewDesigntimeArtifact(artifactType, s.exe)
+	parametersFiles := s.parametersFiles(artifactDir, parametersFile, packageParametersFile)
 
-	exists, err := artifactExists(artifactId, artifactType, packageId, dt, s.ip)
+	tenantVersion, exists, err := artifactExists(artifactId, artifactType, packageId, dt, s.ip)
 	if err != nil {
 		return err
 	}
+	entry.VersionBefore = tenantVersion
 
 	if !exists {
 		log.Info().Msgf("Artifact %v will be created", artifactId)
+		entry.Action = report.Created
+		entry.VersionAfter = manifestVersion(artifactDir)
+		if s.plan != nil {
+			s.plan.Add(plan.Create, artifactType, artifactId, "tenant", fmt.Sprintf("in package %v", packageId))
+			return nil
+		}
 		if artifactType == "Integration" {
 			err = file.UpdateBPMN(artifactDir, scriptMap)
 			if err != nil {
@@ -383,6 +656,15 @@ func (s *Synchroniser) SingleArtifactToTenant(artifactId, artifactName, artifact
 		}
 
 		log.Info().Msg("🏆 Designtime artifact created successfully")
+
+		// The created artifact contains the base parameters, so the environment specific values need to be applied separately
+		if artifactType == "Integration" && s.environment != "" && len(parametersFiles) > 1 {
+			log.Info().Msgf("Updating configured parameter(s) of Integration designtime artifact for environment %v", s.environment)
+			_, err = s.updateConfiguration(artifactId, parametersFiles)
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		log.Info().Msg("Checking if designtime artifact needs to be updated")
 
@@ -392,12 +674,26 @@ func (s *Synchroniser) SingleArtifactToTenant(artifactId, artifactName, artifact
 			return err
 		}
 
-		changesFound, err := compareArtifactContents(workDir, zipFile, artifactDir, scriptMap, dt)
+		diffResult, err := compareArtifactContents(workDir, zipFile, artifactDir, scriptMap, dt)
 		if err != nil {
 			return err
 		}
-
+		changesFound := diffResult.HasDifferences()
+		var diffs []string
+		entry.Action = report.Unchanged
+		entry.VersionAfter = tenantVersion
 		if changesFound {
+			diffs = append(diffs, diffResult.Summary())
+			entry.Action = report.Updated
+			entry.VersionAfter = manifestVersion(artifactDir)
+		}
+
+		if changesFound && s.plan != nil {
+			err = s.planArtifactUpdate(artifactId, artifactType, artifactDir)
+			if err != nil {
+				return err
+			}
+		} else if changesFound {
 			log.Info().Msg("Changes found in designtime artifact. Designtime artifact will be updated in CPI tenant")
 			err = prepareUploadDir(workDir, artifactDir, dt)
 			if err != nil {
@@ -417,6 +713,7 @@ func (s *Synchroniser) SingleArtifactToTenant(artifactId, artifactName, artifact
 			if err != nil {
 				return err
 			}
+			entry.VersionAfter = designtimeVersion
 			if runtimeVersion == designtimeVersion {
 				log.Info().Msg("Undeploying existing runtime artifact with same version number due to changes in design")
 				err = r.UnDeploy(artifactId)
@@ -430,21 +727,111 @@ func (s *Synchroniser) SingleArtifactToTenant(artifactId, artifactName, artifact
 			log.Info().Msg("🏆 No changes detected. Designtime artifact does not need to be updated")
 		}
 
-		if artifactType == "Integration" && file.Exists(parametersFile) {
+		if artifactType == "Integration" && len(parametersFiles) > 0 {
 			log.Info().Msg("Updating configured parameter(s) of Integration designtime artifact where necessary")
-			err = updateConfiguration(artifactId, parametersFile, s.exe)
+			updatedParameters, err := s.updateConfiguration(artifactId, parametersFiles)
 			if err != nil {
 				return err
 			}
+			if len(updatedParameters) > 0 {
+				diffs = append(diffs, fmt.Sprintf("parameters changed: %v", strings.Join(updatedParameters, ", ")))
+				entry.Action = report.Updated
+			}
 		}
+		entry.Diff = strings.Join(diffs, "\n")
+	}
+	return nil
+}
+
+// renderValueMappings converts the downloaded value mappings to the format set for the sync, or else to the format of
+// the value mappings in Git
+func (s *Synchroniser) renderValueMappings(downloadedArtifactPath string, gitArtifactPath string) error {
+	format := s.valueMappingFormat
+	if format == "" {
+		format = file.ValueMappingFormat(gitArtifactPath)
+	}
+	if format == "" || format == file.ValueMappingXML {
+		return nil
+	}
+	log.Info().Msgf("Converting value mappings to %v", format)
+	return file.ConvertValueMappings(downloadedArtifactPath, format)
+}
+
+// diffValueMappingFormat adds a difference for the value mapping file if the format set for the sync differs from the
+// format of the value mappings in Git. Value mappings are compared by their entries, so without it the value mappings
+// in Git would be kept in their current format.
+func (s *Synchroniser) diffValueMappingFormat(result *file.DiffResult, gitArtifactPath string) {
+	if s.valueMappingFormat == "" || file.ValueMappingFormat(gitArtifactPath) == s.valueMappingFormat {
+		return
+	}
+	path := filepath.Base(file.ValueMappingFile(gitArtifactPath, s.valueMappingFormat))
+	for _, f := range result.Files {
+		if f.Path == path {
+			return
+		}
+	}
+	log.Info().Msgf("Value mappings in Git will be converted to %v", s.valueMappingFormat)
+	result.Files = append(result.Files, file.FileDiff{Path: path, Change: file.Changed})
+}
+
+// manifestVersion returns the Bundle-Version in the MANIFEST.MF of the artifact directory, or an empty string if it
+// cannot be read
+func manifestVersion(artifactDir string) string {
+	headers, err := GetManifestHeaders(fmt.Sprintf("%v/META-INF/MANIFEST.MF", artifactDir))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(headers.Get("Bundle-Version"))
+}
+
+// planArtifactUpdate records the update of the designtime artifact, and the undeployment of the runtime artifact if
+// its version matches the version of the updated designtime artifact
+func (s *Synchroniser) planArtifactUpdate(artifactId string, artifactType string, artifactDir string) error {
+	headers, err := GetManifestHeaders(fmt.Sprintf("%v/META-INF/MANIFEST.MF", artifactDir))
+	if err != nil {
+		return err
+	}
+	designtimeVersion := strings.TrimSpace(headers.Get("Bundle-Version"))
+	s.plan.Add(plan.Update, artifactType, artifactId, "tenant", fmt.Sprintf("to version %v", designtimeVersion))
+
+	runtimeVersion, _, err := api.NewRuntime(s.exe).Get(artifactId)
+	if err != nil {
+		return err
+	}
+	if runtimeVersion == designtimeVersion {
+		s.plan.Add(plan.Undeploy, "runtime artifact", artifactId, "tenant", "same version number with changes in design")
 	}
 	return nil
 }
 
-func artifactExists(artifactId string, artifactType string, packageId string, dt api.DesigntimeArtifact, ip *api.IntegrationPackage) (bool, error) {
-	_, _, exists, err := dt.Get(artifactId, "active")
+// packageParametersFile returns the package level parameters overlay file of the environment
+func (s *Synchroniser) packageParametersFile(packageDir string) string {
+	if s.environment == "" {
+		return ""
+	}
+	return fmt.Sprintf("%v/%v/parameters.prop", packageDir, s.environment)
+}
+
+// parametersFiles returns the existing parameters files in increasing order of precedence - base parameters,
+// followed by the package and artifact level overlays of the environment
+func (s *Synchroniser) parametersFiles(artifactDir string, parametersFile string, packageParametersFile string) []string {
+	candidates := []string{parametersFile}
+	if s.environment != "" {
+		candidates = append(candidates, packageParametersFile, fmt.Sprintf("%v/%v/parameters.prop", artifactDir, s.environment))
+	}
+	var files []string
+	for _, f := range candidates {
+		if f != "" && file.Exists(f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func artifactExists(artifactId string, artifactType string, packageId string, dt api.DesigntimeArtifact, ip *api.IntegrationPackage) (string, bool, error) {
+	version, _, exists, err := dt.Get(artifactId, "active")
 	if err != nil {
-		return false, err
+		return "", false, err
 	}
 	if exists {
 		log.Info().Msgf("Active version of artifact %v exists", artifactId)
@@ -452,19 +839,19 @@ func artifactExists(artifactId string, artifactType string, packageId string, dt
 		var details []*api.ArtifactDetails
 		details, err = ip.GetArtifactsData(packageId, artifactType)
 		if err != nil {
-			return false, err
+			return "", false, err
 		}
 		artifact := api.FindArtifactById(artifactId, details)
 		if artifact == nil {
-			return false, fmt.Errorf("Artifact %v not found in package %v", artifactId, packageId)
+			return "", false, fmt.Errorf("Artifact %v not found in package %v", artifactId, packageId)
 		}
 		if artifact.IsDraft {
-			return false, fmt.Errorf("Artifact %v is in Draft state. Save Version of artifact in Web UI first!", artifactId)
+			return "", false, fmt.Errorf("Artifact %v is in Draft state. Save Version of artifact in Web UI first!", artifactId)
 		}
-		return true, nil
+		return version, true, nil
 	} else {
 		log.Info().Msgf("Active version of artifact %v does not exist", artifactId)
-		return false, nil
+		return "", false, nil
 	}
 }
 
@@ -494,67 +881,109 @@ func updateArtifact(artifactId string, artifactName string, packageId string, ar
 	return nil
 }
 
-func compareArtifactContents(workDir string, zipFile string, artifactDir string, scriptMap []string, dt api.DesigntimeArtifact) (bool, error) {
+func compareArtifactContents(workDir string, zipFile string, artifactDir string, scriptMap []string, dt api.DesigntimeArtifact) (*file.DiffResult, error) {
 	tgtDir := fmt.Sprintf("%v/download", workDir)
 	err := os.RemoveAll(tgtDir)
 	if err != nil {
-		return false, errors.Wrap(err, 0)
+		return nil, errors.Wrap(err, 0)
 	}
 
 	log.Info().Msgf("Unzipping downloaded designtime artifact %v to %v/download", zipFile, workDir)
 	err = file.UnzipSource(zipFile, tgtDir)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	return dt.CompareContent(artifactDir, tgtDir, scriptMap, "tenant")
 }
 
-func updateConfiguration(artifactId string, parametersFile string, exe *httpclnt.HTTPExecuter) error {
+// scheduleValue translates the schedule in parameters.prop to the SAP schedule XML format. The tenant value is returned
+// if both schedules trigger at the same times so that no update is done
+func scheduleValue(parameterKey string, fileValue string, tenantValue string) (string, error) {
+	fileSchedule, err := schedule.Parse(fileValue)
+	if err != nil {
+		return "", errors.Wrap(fmt.Errorf("invalid schedule for parameter %v: %w", parameterKey, err), 0)
+	}
+	tenantSchedule, err := schedule.ParseXML(tenantValue)
+	if err == nil && fileSchedule.Equal(tenantSchedule) {
+		return tenantValue, nil
+	}
+	if schedule.IsXML(fileValue) {
+		return fileValue, nil
+	}
+	log.Debug().Msgf("Schedule for parameter %v translated from %v", parameterKey, fileSchedule)
+	return fileSchedule.XML(), nil
+}
+
+// updateConfiguration updates the configured parameters of the artifact that differ from the parameters files, and
+// returns the keys of the updated parameters
+func (s *Synchroniser) updateConfiguration(artifactId string, parametersFiles []string) ([]string, error) {
 	// Get configured parameters from tenant
-	c := api.NewConfiguration(exe)
+	c := api.NewConfiguration(s.exe)
 	tenantParameters, err := c.Get(artifactId, "active")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Get parameters from parameters.prop file
-	log.Info().Msgf("Getting parameters from %v file", parametersFile)
-	fileParameters := properties.MustLoadFile(parametersFile, properties.UTF8).Map()
+	// Get parameters from parameters.prop file(s), values in later files override earlier ones
+	fileParameters, err := loadParameters(parametersFiles)
+	if err != nil {
+		return nil, err
+	}
 
 	log.Info().Msg("Comparing parameters and updating where necessary")
-	atLeastOneUpdated := false
+	var updatedParameters []string
 	for _, result := range tenantParameters.Root.Results {
-		if result.DataType != "custom:schedule" { // TODO - handle translation to Cron
-			// Skip updating for schedulers which require translation to Cron values
-			fileValue := fileParameters[result.ParameterKey]
-			if fileValue != "" && fileValue != result.ParameterValue {
-				log.Info().Msgf("Parameter %v to be updated from %v to %v", result.ParameterKey, result.ParameterValue, fileValue)
-				err = c.Update(artifactId, "active", result.ParameterKey, fileValue)
-				if err != nil {
-					return err
-				}
-				atLeastOneUpdated = true
+		fileValue := fileParameters[result.ParameterKey]
+		if result.DataType == "custom:schedule" && fileValue != "" {
+			fileValue, err = scheduleValue(result.ParameterKey, fileValue, result.ParameterValue)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if fileValue != "" && fileValue != result.ParameterValue {
+			updatedParameters = append(updatedParameters, result.ParameterKey)
+			if s.plan != nil {
+				s.plan.Add(plan.UpdateParameter, "Integration", artifactId, "tenant", fmt.Sprintf("%v from %v to %v", result.ParameterKey, result.ParameterValue, fileValue))
+				continue
+			}
+			log.Info().Msgf("Parameter %v to be updated from %v to %v", result.ParameterKey, result.ParameterValue, fileValue)
+			err = c.Update(artifactId, "active", result.ParameterKey, fileValue)
+			if err != nil {
+				return nil, err
 			}
 		}
 	}
-	if atLeastOneUpdated {
-		r := api.NewRuntime(exe)
+	if len(updatedParameters) > 0 {
+		r := api.NewRuntime(s.exe)
 		version, _, err := r.Get(artifactId)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if version == "NOT_DEPLOYED" {
 			log.Info().Msg("🏆 No existing runtime artifact deployed")
+		} else if s.plan != nil {
+			s.plan.Add(plan.Undeploy, "runtime artifact", artifactId, "tenant", "changes in configured parameters")
 		} else {
 			log.Info().Msg("🏆 Undeploying existing runtime artifact due to changes in configured parameters")
 			err = r.UnDeploy(artifactId)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 	} else {
 		log.Info().Msg("🏆 No updates required for configured parameters")
 	}
-	return nil
+	return updatedParameters, nil
+}
+
+func loadParameters(parametersFiles []string) (map[string]string, error) {
+	for _, f := range parametersFiles {
+		log.Info().Msgf("Getting parameters from %v file", f)
+	}
+	p, err := properties.LoadFiles(parametersFiles, properties.UTF8, false)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return p.Map(), nil
 }
@@ -1,9 +1,13 @@
 package sync
 
 import (
+	"os"
+	"path/filepath"
+	"testing"
+
 	"github.com/engswee/flashpipe/internal/api"
+	"github.com/engswee/flashpipe/internal/file"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestFilterInactive(t *testing.T) {
@@ -63,3 +67,93 @@ func TestFilterExcludeInvalidID(t *testing.T) {
 
 	assert.Equal(t, "Artifact DummyIFlow2 in --ids-exclude does not exist", err.Error(), "Incorrect error message")
 }
+
+// writeFile writes the content to the file, creating its parent directories
+func writeFile(t *testing.T, path string, content string) {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnvironmentParameterOverlays(t *testing.T) {
+	packageDir := t.TempDir()
+	artifactDir := packageDir + "/IFlow1"
+	writeFile(t, artifactDir+"/src/main/resources/parameters.prop", "Host=dev.example.com\nTimeout=60\nUser=devuser\n")
+	writeFile(t, packageDir+"/QA/parameters.prop", "Host=qa.example.com\nUser=qauser\n")
+	writeFile(t, artifactDir+"/QA/parameters.prop", "User=iflow1user\n")
+
+	s := New(nil)
+	s.SetEnvironment("QA")
+	files := s.parametersFiles(artifactDir, artifactDir+"/src/main/resources/parameters.prop", s.packageParametersFile(packageDir))
+	assert.Equal(t, 3, len(files), "Expected number of parameters files = 3")
+
+	parameters, err := loadParameters(files)
+	assert.NoError(t, err)
+	assert.Equal(t, "qa.example.com", parameters["Host"], "Expected package overlay to override base value")
+	assert.Equal(t, "60", parameters["Timeout"], "Expected base value to be kept")
+	assert.Equal(t, "iflow1user", parameters["User"], "Expected artifact overlay to override package overlay")
+}
+
+func TestNoEnvironmentParameterOverlays(t *testing.T) {
+	artifactDir := t.TempDir()
+	writeFile(t, artifactDir+"/src/main/resources/parameters.prop", "Host=dev.example.com\n")
+	writeFile(t, artifactDir+"/QA/parameters.prop", "Host=qa.example.com\n")
+
+	s := New(nil)
+	files := s.parametersFiles(artifactDir, artifactDir+"/src/main/resources/parameters.prop", s.packageParametersFile(artifactDir))
+
+	assert.Equal(t, []string{artifactDir + "/src/main/resources/parameters.prop"}, files, "Expected only base parameters file")
+}
+
+func TestPruneGitArtifacts(t *testing.T) {
+	packageDir := t.TempDir()
+	writeFile(t, packageDir+"/IFlow1/META-INF/MANIFEST.MF", "Bundle-SymbolicName: IFlow1; singleton:=true\n\n")
+	writeFile(t, packageDir+"/IFlow2/META-INF/MANIFEST.MF", "Bundle-SymbolicName: IFlow2; singleton:=true\n\n")
+	writeFile(t, packageDir+"/IFlow3/META-INF/MANIFEST.MF", "Bundle-SymbolicName: IFlow3; singleton:=true\n\n")
+	writeFile(t, packageDir+"/QA/parameters.prop", "Host=qa.example.com\n")
+
+	artifacts := []*api.ArtifactDetails{{Id: "IFlow1"}}
+	err := New(nil).pruneGitArtifacts(packageDir, artifacts, "ID", nil, []string{"IFlow3"})
+
+	assert.NoError(t, err)
+	assert.DirExists(t, packageDir+"/IFlow1", "Artifact in tenant should be kept")
+	assert.NoDirExists(t, packageDir+"/IFlow2", "Artifact not in tenant should be removed")
+	assert.DirExists(t, packageDir+"/IFlow3", "Excluded artifact should be kept")
+	assert.DirExists(t, packageDir+"/QA", "Directory without artifact should be kept")
+}
+
+func TestDiffValueMappingFormat(t *testing.T) {
+	gitArtifactPath := t.TempDir()
+	writeFile(t, gitArtifactPath+"/value_mapping.xml", "<vm/>")
+
+	s := New(nil)
+	result := &file.DiffResult{}
+	s.diffValueMappingFormat(result, gitArtifactPath)
+	assert.False(t, result.HasDifferences(), "Format of Git should be kept if no format is set")
+
+	s.SetValueMappingFormat("xml")
+	s.diffValueMappingFormat(result, gitArtifactPath)
+	assert.False(t, result.HasDifferences(), "Value mappings in the set format should not differ")
+
+	s.SetValueMappingFormat("csv")
+	s.diffValueMappingFormat(result, gitArtifactPath)
+	s.diffValueMappingFormat(result, gitArtifactPath)
+	assert.Equal(t, []file.FileDiff{{Path: "value_mapping.csv", Change: file.Changed}}, result.Files)
+}
+
+func TestPruneGitFiles(t *testing.T) {
+	artifactsDir := t.TempDir()
+	writeFile(t, artifactsDir+"/Product1.json", "{}")
+	writeFile(t, artifactsDir+"/Product2.json", "{}")
+
+	err := pruneGitFiles(Request{ArtifactsDir: artifactsDir}, []string{"Product1"}, ".json", "APIProduct")
+
+	assert.NoError(t, err)
+	assert.FileExists(t, artifactsDir+"/Product1.json", "Product in tenant should be kept")
+	assert.NoFileExists(t, artifactsDir+"/Product2.json", "Product not in tenant should be removed")
+}